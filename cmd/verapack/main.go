@@ -14,6 +14,11 @@ var (
 )
 
 func main() {
+	// git starts verapack as the GIT_ASKPASS program to get the token of an application's git_auth.
+	if verapack.IsGitAskPass() {
+		os.Exit(verapack.RunGitAskPass(os.Args[1:], os.Stdout))
+	}
+
 	check := func(err error) {
 		if err != nil {
 			os.Exit(1)
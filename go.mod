@@ -12,6 +12,8 @@ require (
 	github.com/go-playground/validator/v10 v10.27.0
 	github.com/goccy/go-yaml v1.18.0
 	github.com/urfave/cli/v2 v2.27.7
+	golang.org/x/sys v0.35.0
+	gopkg.in/ini.v1 v1.67.0
 )
 
 require (
@@ -40,8 +42,6 @@ require (
 	github.com/xrash/smetrics v0.0.0-20250705151800-55b8f293f342 // indirect
 	golang.org/x/crypto v0.41.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
-	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/time v0.12.0 // indirect
-	gopkg.in/ini.v1 v1.67.0 // indirect
 )
@@ -0,0 +1,215 @@
+package multiselect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Styles struct {
+	Highlight lipgloss.Style
+	Border    lipgloss.Style
+	Muted     lipgloss.Style
+}
+
+type PostFunc func(selection []int, model Model) (tea.Model, tea.Cmd)
+
+// multiselect.Model is a tea component that gives the user a list of options, of which any number can be selected.
+// It also provides a function that can be run after the selection to perform an action based on the selection.
+// Only the options around the cursor are shown if they do not fit in the window.
+type Model struct {
+	cursor    int
+	selected  map[int]bool
+	options   []string
+	bodyText  string
+	height    int
+	styles    Styles
+	help      help.Model
+	UpKey     key.Binding
+	DownKey   key.Binding
+	ToggleKey key.Binding
+	AllKey    key.Binding
+	EnterKey  key.Binding
+	QuitKey   key.Binding
+	postFunc  PostFunc
+}
+
+type Option func(*Model)
+
+// Selection returns the indexes of the selected options in order.
+func (m Model) Selection() []int {
+	r := make([]int, 0, len(m.selected))
+	for k := range m.options {
+		if m.selected[k] {
+			r = append(r, k)
+		}
+	}
+
+	return r
+}
+
+func (m *Model) toggleAll() {
+	all := len(m.selected) != len(m.options)
+
+	m.selected = make(map[int]bool)
+
+	if all {
+		for k := range m.options {
+			m.selected[k] = true
+		}
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave space for the body text, border and help.
+		m.height = max(msg.Height-strings.Count(m.bodyText, "\n")-8, 3)
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.EnterKey):
+			if m.postFunc == nil {
+				return m, tea.Quit
+			}
+
+			return m.postFunc(m.Selection(), m)
+
+		case key.Matches(msg, m.QuitKey):
+			return m, tea.Quit
+
+		case key.Matches(msg, m.DownKey):
+			if len(m.options) > 0 {
+				m.cursor = (m.cursor + 1) % len(m.options)
+			}
+
+		case key.Matches(msg, m.UpKey):
+			if len(m.options) > 0 {
+				m.cursor = (m.cursor - 1 + len(m.options)) % len(m.options)
+			}
+
+		case key.Matches(msg, m.ToggleKey):
+			if m.selected[m.cursor] {
+				delete(m.selected, m.cursor)
+			} else {
+				m.selected[m.cursor] = true
+			}
+
+		case key.Matches(msg, m.AllKey):
+			m.toggleAll()
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.bodyText + "\n\n")
+
+	start, end := 0, len(m.options)
+	if m.height > 0 && len(m.options) > m.height {
+		start = min(max(m.cursor-m.height/2, 0), len(m.options)-m.height)
+		end = start + m.height
+	}
+
+	for k := start; k < end; k++ {
+		check := " "
+		if m.selected[k] {
+			check = "x"
+		}
+
+		if k == m.cursor {
+			fmt.Fprintf(&b, "%s %s", m.styles.Highlight.Render(">"), m.styles.Highlight.Render(fmt.Sprintf("[%s]  %s", check, m.options[k])))
+		} else {
+			fmt.Fprintf(&b, "  [%s]  %s", check, m.options[k])
+		}
+
+		if k != end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "\n\n%s", m.styles.Muted.Render(fmt.Sprintf("%d of %d selected", len(m.selected), len(m.options))))
+
+	return m.styles.Border.Render(b.String()) + "\n" + m.help.ShortHelpView([]key.Binding{m.QuitKey, m.UpKey, m.DownKey, m.ToggleKey, m.AllKey, m.EnterKey})
+}
+
+func NewModel(options ...Option) Model {
+	m := Model{
+		selected: make(map[int]bool),
+		help:     help.New(),
+		EnterKey: key.NewBinding(
+			key.WithHelp("enter", "submit"),
+			key.WithKeys("enter"),
+		),
+		UpKey: key.NewBinding(
+			key.WithHelp("↑/up", "up"),
+			key.WithKeys("up"),
+		),
+		DownKey: key.NewBinding(
+			key.WithHelp("↓/down", "down"),
+			key.WithKeys("down"),
+		),
+		ToggleKey: key.NewBinding(
+			key.WithHelp("space", "select"),
+			key.WithKeys(" "),
+		),
+		AllKey: key.NewBinding(
+			key.WithHelp("a", "select all"),
+			key.WithKeys("a"),
+		),
+		QuitKey: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("ctrl+c", "quit"),
+		),
+	}
+
+	for _, opt := range options {
+		opt(&m)
+	}
+
+	return m
+}
+
+// WithStyles sets the styles for the multiselect.
+func WithStyles(styles Styles) Option {
+	return func(m *Model) {
+		m.styles = styles
+	}
+}
+
+func WithHelp(help help.Model) Option {
+	return func(m *Model) {
+		m.help = help
+	}
+}
+
+// WithOptions sets the options for the multiselect.
+func WithOptions(options ...string) Option {
+	return func(m *Model) {
+		m.options = options
+	}
+}
+
+// WithBodyText sets the body text for the multiselect.
+func WithBodyText(text string) Option {
+	return func(m *Model) {
+		m.bodyText = text
+	}
+}
+
+func WithPostFunc(postFunc PostFunc) Option {
+	return func(m *Model) {
+		m.postFunc = postFunc
+	}
+}
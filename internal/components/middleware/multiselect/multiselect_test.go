@@ -0,0 +1,92 @@
+package multiselect
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends the keys to the model, in order, and returns the updated model.
+func press(m Model, keys ...tea.KeyMsg) Model {
+	for _, k := range keys {
+		updated, _ := m.Update(k)
+		m = updated.(Model)
+	}
+
+	return m
+}
+
+var (
+	up     = tea.KeyMsg{Type: tea.KeyUp}
+	down   = tea.KeyMsg{Type: tea.KeyDown}
+	toggle = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	all    = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}}
+)
+
+func TestModel_Selection(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want []int
+	}{
+		{name: "nothing selected", want: []int{}},
+		{name: "selection is in order", keys: []tea.KeyMsg{down, down, toggle, up, up, toggle}, want: []int{0, 2}},
+		{name: "toggle twice", keys: []tea.KeyMsg{toggle, toggle}, want: []int{}},
+		{name: "cursor wraps around", keys: []tea.KeyMsg{up, toggle, down, down, toggle}, want: []int{1, 2}},
+		{name: "select all", keys: []tea.KeyMsg{all}, want: []int{0, 1, 2}},
+		{name: "select all after a selection", keys: []tea.KeyMsg{toggle, all}, want: []int{0, 1, 2}},
+		{name: "deselect all", keys: []tea.KeyMsg{all, all}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(NewModel(WithOptions("A", "B", "C")), tt.keys...)
+
+			if got := m.Selection(); !slices.Equal(got, tt.want) {
+				t.Errorf("Selection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_PostFunc(t *testing.T) {
+	var got []int
+
+	m := NewModel(WithOptions("A", "B", "C"), WithPostFunc(func(selection []int, model Model) (tea.Model, tea.Cmd) {
+		got = selection
+		return model, tea.Quit
+	}))
+
+	m = press(m, down, toggle)
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Errorf("Update(enter) did not return the command of the post function")
+	}
+
+	if want := []int{1}; !slices.Equal(got, want) {
+		t.Errorf("post function got selection %v, want %v", got, want)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	options := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+
+	m := NewModel(WithOptions(options...), WithBodyText("Select:"))
+	m = press(m, toggle)
+
+	if view := m.View(); !strings.Contains(view, "[x]  A") || !strings.Contains(view, "[ ]  H") || !strings.Contains(view, "1 of 8 selected") {
+		t.Errorf("View() = %q, want all of the options and the number of selected options", view)
+	}
+
+	// Only the options around the cursor are shown if they do not fit in the window.
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 11})
+	m = press(updated.(Model), down, down, down, down, down)
+
+	view := m.View()
+	for _, option := range options {
+		if shown := strings.Contains(view, "]  "+option); shown != (option >= "E" && option <= "G") {
+			t.Errorf("View() shows option %s = %t, want only the options around the cursor:\n%s", option, shown, view)
+		}
+	}
+}
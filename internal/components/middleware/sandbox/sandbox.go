@@ -51,6 +51,16 @@ type SandboxOptions struct {
 	SandboxId   *int
 	SandboxGuid *string
 	AppGuid     *string
+	Client      *veracode.Client // Client to use for this application. If nil, the Model's client is used.
+}
+
+// clientFor returns the client to use for the application at index appIndex.
+func (m Model) clientFor(appIndex int) *veracode.Client {
+	if client := m.Applications[appIndex].Client; client != nil {
+		return client
+	}
+
+	return m.client
 }
 
 func (m Model) Init() tea.Cmd {
@@ -58,7 +68,7 @@ func (m Model) Init() tea.Cmd {
 	cmds = append(cmds, tea.ClearScreen, m.spinner.Tick)
 
 	for k := range m.Applications {
-		cmds = append(cmds, SearchApplication(m.Applications[k].AppName, k, m.client, m.ctx))
+		cmds = append(cmds, SearchApplication(m.Applications[k].AppName, k, m.clientFor(k), m.ctx))
 	}
 
 	return tea.Batch(cmds...)
@@ -99,7 +109,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		*m.Applications[msg.appIndex].AppGuid = msg.appGuid
 
 		// application was found, therefore perform the next step which is searching for an existing sandbox.
-		cmds = append(cmds, SearchSandbox(msg.appGuid, m.Applications[msg.appIndex].SandboxName, msg.appIndex, m.client, msg.ctx))
+		cmds = append(cmds, SearchSandbox(msg.appGuid, m.Applications[msg.appIndex].SandboxName, msg.appIndex, m.clientFor(msg.appIndex), msg.ctx))
 
 	case sandboxSearchMsg:
 		// 2. this is the second msg that will be returned
@@ -129,7 +139,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		*m.creatingCount++
-		cmds = append(cmds, CreateSandbox(msg.appGuid, msg.sandboxName, msg.appIndex, m.client, msg.ctx))
+		cmds = append(cmds, CreateSandbox(msg.appGuid, msg.sandboxName, msg.appIndex, m.clientFor(msg.appIndex), msg.ctx))
 
 	case sandboxCreateMsg:
 		// 3. this is the third msg that will be returned
@@ -218,6 +228,7 @@ func (m *Model) GetErrors() []error {
 	return m.errs
 }
 
+// NewModel creates a new Model. client is used for the applications that do not set [SandboxOptions].Client.
 func NewModel(applications []SandboxOptions, client *veracode.Client, ctx context.Context, options ...Option) Model {
 	cc := 0
 	ci := 0
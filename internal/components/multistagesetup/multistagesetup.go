@@ -148,6 +148,8 @@ type Model struct {
 	termWidth          int
 	finalMessage       string
 	isSuccessfullyDone bool
+	continueOnFailure  bool // continueOnFailure runs the remaining tasks after a task fails, instead of quitting.
+	hasFailures        bool
 	values             map[string]any
 }
 
@@ -166,7 +168,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	}
 
-	var newTask bool
+	var newTask, taskDone bool
 
 	switch msgt := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -198,26 +200,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msgt.Status {
 		case SetupTaskSuccess, SetupTaskWarning, SetupTaskSkipped:
 			m.tasks[m.activeTask].status = msgt.Status
+			taskDone = true
 
-			if m.activeTask+1 < len(m.tasks) {
-				// There are still more tasks in the list
-				m.activeTask++
-				m.tasks[m.activeTask].status = SetupTaskInProgress
-				newTask = true
-				m.setTaskFullHelpAvailable()
-			} else {
-				// There are no more tasks
-				m.isSuccessfullyDone = true
-				return m, tea.Quit
-			}
 		case SetupTaskFailure:
 			m.tasks[m.activeTask].status = SetupTaskFailure
 			m.tasks[m.activeTask].err = msgt.Err
+			m.hasFailures = true
+
+			if !m.continueOnFailure {
+				// re-run update and view one more time before quitting
+				return m, tea.Sequence(
+					func() tea.Msg { return nil }, tea.Quit,
+				)
+			}
+			taskDone = true
+		}
 
-			// re-run update and view one more time before quitting
-			return m, tea.Sequence(
-				func() tea.Msg { return nil }, tea.Quit,
-			)
+		if !taskDone {
+			break
+		}
+
+		if m.activeTask+1 < len(m.tasks) {
+			// There are still more tasks in the list
+			m.activeTask++
+			m.tasks[m.activeTask].status = SetupTaskInProgress
+			newTask = true
+			m.setTaskFullHelpAvailable()
+		} else {
+			// There are no more tasks
+			m.isSuccessfullyDone = !m.hasFailures
+			return m, tea.Quit
 		}
 	}
 
@@ -246,6 +258,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// HasFailures returns whether any of the tasks failed.
+func (m Model) HasFailures() bool {
+	return m.hasFailures
+}
+
 func (m *Model) setTaskFullHelpAvailable() {
 	if len(m.getTaskFullHelp()) > 0 {
 		m.KeyMap.Help.SetEnabled(true)
@@ -379,6 +396,14 @@ func WithKeyMap(km KeyMap) Option {
 	}
 }
 
+// WithContinueOnFailure runs all of the tasks, even if one of them fails.
+// The final message will only be shown if none of the tasks failed.
+func WithContinueOnFailure() Option {
+	return func(m *Model) {
+		m.continueOnFailure = true
+	}
+}
+
 func WithFinalMessage(s string) Option {
 	return func(m *Model) {
 		m.finalMessage = s
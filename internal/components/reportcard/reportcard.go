@@ -49,7 +49,7 @@ type TaskResultMsg struct {
 	Index               int              // Index is the index of the item in [Model].rows.
 	CustomSuccessStatus CustomTaskStatus // CustomSuccessStatus contains options for replacing the normal success symbol.
 	Output              any              // Output will be passed to the viewport renderer.
-	ViewportName        string           // TODO: Implement Feature. Allows the caller to select a custom [Viewport] rendered to use. Will use the default viewport if value is empty or name could not be matched.
+	ViewportName        string           // ViewportName allows the caller to select a custom [Viewport] to render the output, see [WithViewport]. Will use the default viewport if value is empty or name could not be matched.
 	ForceDefault        bool             // ForceDefault allows the caller to set this task's output as the default output when navigating to this row in the reportcard. Requires Output to be set.
 }
 
@@ -162,26 +162,33 @@ func (r *Row) update(msg TaskResultMsg) (PrevRowStatus, CurRowStatus RowStatus)
 	if msg.Output != nil {
 		r.tasks[r.activeTaskIndex].hasOutput = true
 		r.tasks[r.activeTaskIndex].viewportInputData = msg.Output
+		r.tasks[r.activeTaskIndex].viewportName = msg.ViewportName
 		r.setDefaultDisplayTask(r.activeTaskIndex, msg)
 	}
 
 	var rowNotDone bool
 
+	failedTaskIndex := r.activeTaskIndex
+
 	for k := r.activeTaskIndex + 1; k < len(r.tasks); k++ {
 		if r.tasks[k].status != NotStarted {
 			continue
 		}
 
 		if msg.Status == Failure {
-			if r.canTaskRunAnyway(k) {
-				// If the original/active task failed, but the current
-				// task is allowed to run anyway, change its status to
-				// InProgress.
+			if !r.canTaskRunAnyway(k, failedTaskIndex) {
+				r.tasks[k].status = Skip
+				continue
+			}
+
+			// If the original/active task failed, but the current
+			// task is allowed to run anyway, change its status to
+			// InProgress. Any other tasks that are allowed to run
+			// anyway, wait for it to complete.
+			if !rowNotDone {
 				r.tasks[k].status = InProgress
 				r.activeTaskIndex = k
 				rowNotDone = true
-			} else {
-				r.tasks[k].status = Skip
 			}
 		} else {
 			r.tasks[k].status = InProgress
@@ -214,9 +221,9 @@ func (r *Row) update(msg TaskResultMsg) (PrevRowStatus, CurRowStatus RowStatus)
 }
 
 // canTaskRunAnyway checks whether the task with the provided index can run despite
-// the current active task failing.
-func (r *Row) canTaskRunAnyway(taskIndex int) bool {
-	return slices.Contains(r.tasks[taskIndex].shouldRunAnywayFor, r.tasks[r.activeTaskIndex].name)
+// the task with index failedTaskIndex failing.
+func (r *Row) canTaskRunAnyway(taskIndex, failedTaskIndex int) bool {
+	return slices.Contains(r.tasks[taskIndex].shouldRunAnywayFor, r.tasks[failedTaskIndex].name)
 }
 
 // setSelected sets the selectedTaskIndex to the defaultTaskIndex.
@@ -362,7 +369,7 @@ type Task struct {
 	// values are bools indicating whether the task should run for said task.
 	shouldRunAnywayFor  []string
 	hasOutput           bool   // Should show Output. Can be selected.
-	viewportName        string // TODO: Implement Feature. Implement custom viewport setup. If left empty, will use the default viewport.
+	viewportName        string // Name of the custom viewport that renders the output. If left empty, will use the default viewport.
 	viewportInputData   any    // Data that will be injected into the viewport for the output rendering.
 	customSuccessStatus CustomTaskStatus
 }
@@ -465,6 +472,8 @@ type Model struct {
 	pageCurrentEnd           int  // the last row on the current page
 	statusCounts             map[RowStatus]int
 	defaultViewport          Viewport
+	viewports                map[string]Viewport     // Custom viewports, see [WithViewport].
+	activeViewportName       string                  // Name of the custom viewport that is showing the output. Empty if the default viewport is showing it.
 	customActions            map[string]CustomAction // [CustomKeys].GetCustomActionName() should return a string that matches one of these keys.
 	viewportWidthMultiplier  float64                 // width multiplier of the viewport. Viewport width will be set to this value * the terminal width. Default value: 0.6
 	viewportHeightMultiplier float64                 // height multiplier of the viewport. Viewport height will be set to this value * the terminal height. Default value: 0.3
@@ -532,6 +541,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.termHeight = msg.Height
 
 		m.defaultViewport.SetDimensions(int(float64(m.termWidth)*m.viewportWidthMultiplier), int(float64(m.termHeight)*m.viewportHeightMultiplier))
+		for _, v := range m.viewports {
+			v.SetDimensions(int(float64(m.termWidth)*m.viewportWidthMultiplier), int(float64(m.termHeight)*m.viewportHeightMultiplier))
+		}
 
 	case tea.KeyMsg:
 		var shouldSetActiveKeys, shouldUpdateOutput bool
@@ -587,27 +599,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.KeyMap.Down):
 			// While output is shown, scroll down
-			cmds = append(cmds, m.defaultViewport.LineDown(1))
+			cmds = append(cmds, m.activeViewport().LineDown(1))
 
 		case key.Matches(msg, m.KeyMap.Up):
 			// While output is shown, scroll up
-			cmds = append(cmds, m.defaultViewport.LineUp(1))
+			cmds = append(cmds, m.activeViewport().LineUp(1))
 
 		case key.Matches(msg, m.KeyMap.PageDown):
 			// While output is shown, scroll page down
-			cmds = append(cmds, m.defaultViewport.ViewDown())
+			cmds = append(cmds, m.activeViewport().ViewDown())
 
 		case key.Matches(msg, m.KeyMap.PageUp):
 			// While output is shown, scroll page up
-			cmds = append(cmds, m.defaultViewport.ViewUp())
+			cmds = append(cmds, m.activeViewport().ViewUp())
 
 		case key.Matches(msg, m.KeyMap.HalfPageDown):
 			// While output is shown, scroll half page down
-			cmds = append(cmds, m.defaultViewport.HalfViewDown())
+			cmds = append(cmds, m.activeViewport().HalfViewDown())
 
 		case key.Matches(msg, m.KeyMap.HalfPageUp):
 			// While output is shown, scroll half page up
-			cmds = append(cmds, m.defaultViewport.HalfViewUp())
+			cmds = append(cmds, m.activeViewport().HalfViewUp())
 		}
 
 		if shouldSetActiveKeys {
@@ -640,6 +652,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	cmds = append(cmds, cmd)
 	m.defaultViewport, cmd = m.defaultViewport.Update(msg)
 	cmds = append(cmds, cmd)
+	if v, ok := m.viewports[m.activeViewportName]; ok {
+		m.viewports[m.activeViewportName], cmd = v.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -730,18 +746,31 @@ func (m *Model) setOutput() tea.Cmd {
 	if r := m.rows[m.selectedRow]; r.hasOutputToDisplay() && m.showOutput {
 		viewportName, content := r.getOutput()
 
-		switch viewportName {
-		default:
-			if !m.defaultViewport.HasBeenInitialized() {
-				return m.defaultViewport.Init(int(float64(m.termWidth)*m.viewportWidthMultiplier), int(float64(m.termHeight)*m.viewportHeightMultiplier), content)
-			}
-			m.defaultViewport.SetContent(content)
+		if _, ok := m.viewports[viewportName]; ok {
+			m.activeViewportName = viewportName
+		} else {
+			m.activeViewportName = ""
+		}
+
+		v := m.activeViewport()
+		if !v.HasBeenInitialized() {
+			return v.Init(int(float64(m.termWidth)*m.viewportWidthMultiplier), int(float64(m.termHeight)*m.viewportHeightMultiplier), content)
 		}
+		v.SetContent(content)
 	}
 
 	return nil
 }
 
+// activeViewport returns the [Viewport] that is showing the output of the selected task.
+func (m Model) activeViewport() Viewport {
+	if v, ok := m.viewports[m.activeViewportName]; ok {
+		return v
+	}
+
+	return m.defaultViewport
+}
+
 func (m Model) View() string {
 	// length of rows includes the header
 	rows := make([]string, 0, len(m.rows)+1)
@@ -787,7 +816,7 @@ func (m Model) renderHelp() string {
 
 func (m Model) renderOutputScrollBar(arrowStyle, keyStyle lipgloss.Style) string {
 	var s string
-	if m.defaultViewport.AtTop() {
+	if m.activeViewport().AtTop() {
 		s += arrowStyle.Render("┬")
 	} else {
 		s += arrowStyle.Render("↟") + " " + keyStyle.Render(strings.Join(m.KeyMap.PageUp.Keys(), ",")) + "\n"
@@ -796,7 +825,7 @@ func (m Model) renderOutputScrollBar(arrowStyle, keyStyle lipgloss.Style) string
 
 	s += "\n"
 
-	if m.defaultViewport.AtBottom() {
+	if m.activeViewport().AtBottom() {
 		s += arrowStyle.Render("┴")
 	} else {
 		s += arrowStyle.Render("↓") + " " + keyStyle.Render(strings.Join(m.KeyMap.Down.Keys(), ",")) + "\n"
@@ -810,9 +839,9 @@ func (m Model) renderOutput() string {
 	var output string
 
 	if m.showOutput {
-		output = m.styles.Border.Render(fmt.Sprintf("%s\n\n", lipgloss.NewStyle().Bold(true).Render("Output")) + m.defaultViewport.View())
+		output = m.styles.Border.Render(fmt.Sprintf("%s\n\n", lipgloss.NewStyle().Bold(true).Render("Output")) + m.activeViewport().View())
 
-		if m.defaultViewport.ShouldShowScrollBar() {
+		if m.activeViewport().ShouldShowScrollBar() {
 			output = lipgloss.JoinHorizontal(
 				lipgloss.Center,
 				output,
@@ -1134,6 +1163,18 @@ func WithViewportDimensions(widthModifier, heightModifier float64) Option {
 	}
 }
 
+// WithViewport adds a custom [Viewport] that renders the output of the tasks whose [TaskResultMsg].ViewportName
+// matches name.
+func WithViewport(name string, v Viewport) Option {
+	return func(m *Model) {
+		if m.viewports == nil {
+			m.viewports = make(map[string]Viewport)
+		}
+
+		m.viewports[name] = v
+	}
+}
+
 func WithHelp(help help.Model) Option {
 	return func(m *Model) {
 		m.Help = help
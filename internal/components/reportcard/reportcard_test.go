@@ -113,6 +113,17 @@ func Test_row_update(t *testing.T) {
 			wantRowStatus: RowFailure,
 			wantRowTasks:  []Task{{status: Failure, name: "t1"}, {status: Skip, name: "t2"}, {status: Success, name: "t3", shouldRunAnywayFor: []string{"t1", "t2"}}, {status: Skip, name: "t4"}},
 		},
+		{
+			name: "multiple can run anyway, run one after the other",
+			r: &Row{
+				status:           RowNotStarted,
+				columnsReference: map[string]int{"t1": 0, "t2": 1, "t3": 2, "t4": 3},
+				tasks:            []Task{{status: NotStarted, name: "t1"}, {status: NotStarted, name: "t2", shouldRunAnywayFor: []string{"t1"}}, {status: NotStarted, name: "t3"}, {status: NotStarted, name: "t4", shouldRunAnywayFor: []string{"t1", "t2"}}},
+			},
+			args:          []arg{{uf: uf, msg: TaskResultMsg{Status: Failure}}, {uf: uf, msg: TaskResultMsg{Status: Success}}},
+			wantRowStatus: RowStarted,
+			wantRowTasks:  []Task{{status: Failure, name: "t1"}, {status: Success, name: "t2", shouldRunAnywayFor: []string{"t1"}}, {status: Skip, name: "t3"}, {status: InProgress, name: "t4", shouldRunAnywayFor: []string{"t1", "t2"}}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -6,12 +6,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
-	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/DanCreative/verapack/internal/components/middleware/multiselect"
 	sand "github.com/DanCreative/verapack/internal/components/middleware/sandbox"
 	"github.com/DanCreative/verapack/internal/components/middleware/singleselect"
 	"github.com/DanCreative/verapack/internal/components/multistagesetup"
@@ -76,11 +75,116 @@ func NewApp() *cli.App {
 						Name:   "refresh",
 						Usage:  "Automatically re-generate your API credentials and update the credential files",
 						Action: refreshCredentials,
+						Flags:  []cli.Flag{profileFlag()},
 					},
 					{
 						Name:   "configure",
 						Usage:  "Configure new credentials manually (Used for when existing credentials have expired or for when switching accounts)",
 						Action: configureCredentials,
+						Flags:  []cli.Flag{profileFlag()},
+					},
+				},
+			},
+			{
+				Name:   "doctor",
+				Usage:  "Run diagnostics on the environment, installed tools, credentials and config file",
+				Action: doctor,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the results as JSON",
+					},
+				},
+			},
+			{
+				Name:  "profiles",
+				Usage: "Options for managing the application profiles",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "sync",
+						Usage:     "Create missing application profiles and update the ones that differ from their profile block in the config file",
+						Action:    syncProfiles,
+						Args:      true,
+						ArgsUsage: "[APPLICATION...]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Only show the changes, without changing the application profiles",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Options for managing the config file",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "import",
+						Usage:  "Add application profiles from the platform to the applications in the config file",
+						Action: importConfig,
+						Flags: []cli.Flag{
+							profileFlag(),
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "Only list application profiles whose name matches the pattern. The * wildcard matches 0 or more characters",
+							},
+							&cli.StringFlag{
+								Name:  "team",
+								Usage: "Only list application profiles of the team",
+							},
+							&cli.StringFlag{
+								Name:  "business-unit",
+								Usage: "Only list application profiles of the business unit",
+							},
+						},
+					},
+					{
+						Name:   "edit",
+						Usage:  "Edit the default settings and applications in the config file with an interactive editor",
+						Action: editConfig,
+					},
+					{
+						Name:   "show",
+						Usage:  "Print the config file with its includes merged into it, and the file that every value is from",
+						Action: showConfig,
+					},
+					{
+						Name:   "schema",
+						Usage:  "Print the JSON Schema of the config file, for editors that support yaml-language-server",
+						Action: printSchema,
+					},
+					{
+						Name:   "migrate",
+						Usage:  "Migrate the config file to the current config_version, after writing a backup of it",
+						Action: migrateConfig,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "Path of the config file to migrate, e.g. an included config file. Defaults to the config file of verapack",
+							},
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print the changes without saving them",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "Options for managing the clone cache",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "prune",
+						Usage:  "Remove repositories from the clone cache that are unused or exceed the size limit",
+						Action: pruneCache,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Remove all of the repositories from the clone cache",
+							},
+						},
 					},
 				},
 			},
@@ -102,14 +206,26 @@ func setup(cCtx *cli.Context) error {
 		return err
 	}
 
+	mirrors, err := LoadMirrors(filepath.Join(appDir, "config.yaml"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	store, err := LoadCredentialsStore(homeDir)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
 	p := tea.NewProgram(PrepareSetup(appDir, []multistagesetup.SetupTask{
 		Prerequisites(),
-		SetupCredentialsUserPrompt(veracode.LoadVeracodeCredentials),
+		SetupCredentialsUserPrompt(func() (string, string, error) { return store.get("") }),
 		SetupCredentialsFile(homeDir),
 		SetupCredentialsFileLegacy(homeDir),
 		SetupConfig(homeDir, appDir),
-		SetupInstallDependencyPackager(),
-		SetupInstallDependencyWrapper(),
+		SetupInstallDependencyPackager(mirrors.Packager),
+		SetupInstallDependencyWrapper(mirrors.Wrapper),
 		SetupInstallScaAgent(),
 	}))
 
@@ -121,10 +237,22 @@ func setup(cCtx *cli.Context) error {
 }
 
 func update(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	mirrors, err := LoadMirrors(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
 	p := tea.NewProgram(PrepareUpdate([]multistagesetup.SetupTask{
 		Prerequisites(),
-		UpdateDependencyPackager(),
-		UpdateDependencyWrapper(),
+		UpdateDependencyPackager(mirrors.Packager),
+		UpdateDependencyWrapper(mirrors.Wrapper),
 		SetupInstallScaAgent()}))
 	if _, err := p.Run(); err != nil {
 		return err
@@ -156,12 +284,16 @@ func sandbox(cCtx *cli.Context) error {
 		return err
 	}
 
-	client, err := NewVeracodeClient()
+	clients, err := NewVeracodeClients(c.Applications)
 	if err != nil {
 		fmt.Print(renderErrors(err))
 		return err
 	}
 
+	if err = checkCredentialExpiry(clients, c, homeDir); err != nil {
+		return err
+	}
+
 	startChan := make(chan struct{})
 	var m tea.Model
 	ctx := context.Background()
@@ -198,8 +330,8 @@ func sandbox(cCtx *cli.Context) error {
 					RemoveBadApps(&c, badApps)
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
-						client,
+						appsToSandboxOptions(c.Applications, clients),
+						nil,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
 						sand.WithErrorRenderFunc(rawRenderErrors),
@@ -236,8 +368,8 @@ func sandbox(cCtx *cli.Context) error {
 	} else {
 		// All apps are correct.
 		m = sand.NewModel(
-			appsToSandboxOptions(c.Applications),
-			client,
+			appsToSandboxOptions(c.Applications, clients),
+			nil,
 			ctx,
 			sand.WithSpinner(defaultSpinnerOpts...),
 			sand.WithErrorRenderFunc(rawRenderErrors),
@@ -263,12 +395,13 @@ func sandbox(cCtx *cli.Context) error {
 
 	go func() {
 		<-startChan
+		shared := groupSharedClones(c.Applications)
 		for k, app := range c.Applications {
 			go func() {
 				if app.ScanType == ScanTypePromote {
-					promoteSandbox(client, ctx, app, k, p)
+					promoteSandbox(clients.For(app).Client, ctx, app, k, p)
 				} else {
-					packageAndUploadApplication(uploaderPath, app, k, p, client, ctx)
+					packageAndUploadApplication(uploaderPath, app, k, p, clients.For(app), shared[k], ctx)
 				}
 			}()
 		}
@@ -280,6 +413,8 @@ func sandbox(cCtx *cli.Context) error {
 		return err
 	}
 
+	pruneCloneCacheAfterScan(c)
+
 	if s, ok := m.(sand.Model); ok {
 		if errs := s.GetErrors(); len(errs) > 0 {
 			return err
@@ -302,12 +437,16 @@ func promote(cCtx *cli.Context) error {
 		return err
 	}
 
-	client, err := NewVeracodeClient()
+	clients, err := NewVeracodeClients(c.Applications)
 	if err != nil {
 		fmt.Print(renderErrors(err))
 		return err
 	}
 
+	if err = checkCredentialExpiry(clients, c, homeDir); err != nil {
+		return err
+	}
+
 	startChan := make(chan struct{})
 	var m tea.Model
 	ctx := context.Background()
@@ -365,8 +504,8 @@ func promote(cCtx *cli.Context) error {
 					RemoveBadApps(&c, badApps)
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
-						client,
+						appsToSandboxOptions(c.Applications, clients),
+						nil,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
 						sand.WithErrorRenderFunc(rawRenderErrors),
@@ -402,8 +541,8 @@ func promote(cCtx *cli.Context) error {
 					}
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
-						client,
+						appsToSandboxOptions(c.Applications, clients),
+						nil,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
 						sand.WithErrorRenderFunc(rawRenderErrors),
@@ -453,8 +592,8 @@ func promote(cCtx *cli.Context) error {
 	} else {
 		// m = sand.NewModel(appsToSandboxOptions(c.Applications), client, ctx)
 		m = sand.NewModel(
-			appsToSandboxOptions(c.Applications),
-			client,
+			appsToSandboxOptions(c.Applications, clients),
+			nil,
 			ctx,
 			sand.WithSpinner(defaultSpinnerOpts...),
 			sand.WithErrorRenderFunc(rawRenderErrors),
@@ -480,12 +619,13 @@ func promote(cCtx *cli.Context) error {
 
 	go func() {
 		<-startChan
+		shared := groupSharedClones(c.Applications)
 		for k, app := range c.Applications {
 			go func() {
 				if app.ScanType == ScanTypePromote {
-					promoteSandbox(client, ctx, app, k, p)
+					promoteSandbox(clients.For(app).Client, ctx, app, k, p)
 				} else {
-					packageAndUploadApplication(uploaderPath, app, k, p, client, ctx)
+					packageAndUploadApplication(uploaderPath, app, k, p, clients.For(app), shared[k], ctx)
 				}
 			}()
 		}
@@ -497,6 +637,8 @@ func promote(cCtx *cli.Context) error {
 		return err
 	}
 
+	pruneCloneCacheAfterScan(c)
+
 	if s, ok := m.(sand.Model); ok {
 		if errs := s.GetErrors(); len(errs) > 0 {
 			return err
@@ -528,19 +670,24 @@ func policy(cCtx *cli.Context) error {
 	path := os.Getenv("PATH")
 	os.Setenv("PATH", path+";"+getPackagerLocation())
 
-	client, err := NewVeracodeClient()
+	clients, err := NewVeracodeClients(c.Applications)
 	if err != nil {
 		fmt.Print(renderErrors(err))
 		return err
 	}
 
+	if err = checkCredentialExpiry(clients, c, homeDir); err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 
 	p := tea.NewProgram(PrepareReportCard(c))
 
+	shared := groupSharedClones(c.Applications)
 	for k, app := range c.Applications {
 		go func() {
-			packageAndUploadApplication(uploaderPath, app, k, p, client, ctx)
+			packageAndUploadApplication(uploaderPath, app, k, p, clients.For(app), shared[k], ctx)
 		}()
 	}
 
@@ -548,6 +695,8 @@ func policy(cCtx *cli.Context) error {
 		return err
 	}
 
+	pruneCloneCacheAfterScan(c)
+
 	return nil
 }
 
@@ -558,13 +707,15 @@ func refreshCredentials(cCtx *cli.Context) error {
 		return err
 	}
 
-	client, err := NewVeracodeClient()
+	profile := cCtx.String("profile")
+
+	client, err := NewVeracodeProfileClient(profile)
 	if err != nil {
 		fmt.Print(renderErrors(err))
 		return err
 	}
 
-	p := tea.NewProgram(NewCredentialsRefreshModel(client, homeDir))
+	p := tea.NewProgram(NewCredentialsRefreshModel(client.Client, homeDir, profile))
 	if _, err := p.Run(); err != nil {
 		return err
 	}
@@ -578,14 +729,51 @@ func configureCredentials(cCtx *cli.Context) error {
 		return err
 	}
 
-	p := tea.NewProgram(NewCredentialsConfigureModel(NewCredentialsTask(veracode.LoadVeracodeCredentials), homeDir))
+	profile := cCtx.String("profile")
+
+	p := tea.NewProgram(NewCredentialsConfigureModel(NewCredentialsTask(func() (string, string, error) {
+		return loadProfileCredentials(profile)
+	}), homeDir, profile))
 	if _, err := p.Run(); err != nil {
 		return err
 	}
 	return nil
 }
 
+func doctor(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	if cCtx.Bool("json") {
+		return WriteDoctorJSON(homeDir, os.Stdout)
+	}
+
+	p := tea.NewProgram(PrepareDoctor(DoctorTasks(homeDir)))
+
+	m, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	if s, ok := m.(multistagesetup.Model); ok && s.HasFailures() {
+		return errors.New("one or more doctor checks failed")
+	}
+
+	return nil
+}
+
 func VersionPrinter(cCtx *cli.Context) {
+	var mirrors Mirrors
+
+	// The version should still be printed if the mirrors can't be loaded. In that case, the
+	// latest version checks will fall back to the default sources.
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		mirrors, _ = LoadMirrors(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	}
+
 	jar, _ := cookiejar.New(&cookiejar.Options{})
 
 	httpClient := &http.Client{
@@ -598,11 +786,10 @@ func VersionPrinter(cCtx *cli.Context) {
 		cCtx.App.Version,
 		cCtx.App.Name,
 		func() (string, error) {
-			return GetLatestUploaderVersion(httpClient)
+			return GetLatestUploaderVersion(httpClient, mirrors.Wrapper)
 		},
 		func() (string, error) {
-			baseURL, _ := url.Parse("https://tools.veracode.com/veracode-cli")
-			return GetLatestPackagerVersion(httpClient, baseURL)
+			return GetLatestPackagerVersion(httpClient, mirrors.Packager)
 		},
 		version.WithSpinner([]spinner.Option{
 			spinner.WithSpinner(spinner.Spinner{
@@ -621,6 +808,273 @@ func VersionPrinter(cCtx *cli.Context) {
 	tea.NewProgram(m).Run()
 }
 
+func pruneCache(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	options, err := LoadCacheOptions(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	cacheDir, err := getCloneCacheDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	r, err := PruneCloneCache(cacheDir, options, cCtx.Bool("all"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	fmt.Printf("Removed %d repositories (%s). %d repositories remaining (%s).\n", r.Removed, formatBytes(r.FreedBytes), r.Remaining, formatBytes(r.SizeBytes))
+
+	return nil
+}
+
+func syncProfiles(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	c, err := ReadConfig(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"), cCtx.Args().Slice()...)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	clients, err := NewVeracodeClients(c.Applications)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	return SyncProfiles(context.Background(), c.Applications, clients, cCtx.Bool("dry-run"), os.Stdout)
+}
+
+func importConfig(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	configPath := filepath.Join(homeDir, ".veracode", "verapack", "config.yaml")
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	// The applications of the included files are configured as well.
+	merged, _, err := LoadConfigFile(configPath)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	configured, err := configuredApplicationNames(merged)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	// The applications that were imported before, but have not been completed yet, are not listed again.
+	configured = append(configured, commentedApplicationNames(content)...)
+
+	credentialsProfile := cCtx.String("profile")
+
+	clients, err := NewVeracodeClients([]Options{{CredentialsProfile: credentialsProfile}})
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	names, err := ListApplicationProfiles(context.Background(), clients.For(Options{CredentialsProfile: credentialsProfile}).Client, ImportFilter{
+		Name:         cCtx.String("name"),
+		Team:         cCtx.String("team"),
+		BusinessUnit: cCtx.String("business-unit"),
+	}, configured)
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	if len(names) == 0 {
+		fmt.Println("There are no application profiles that are not in the config file already.")
+		return nil
+	}
+
+	var selection []int
+
+	m := multiselect.NewModel(
+		multiselect.WithHelp(defaultHelp),
+		multiselect.WithBodyText("Select the application profiles to add to the config file:"),
+		multiselect.WithOptions(names...),
+		multiselect.WithStyles(multiselect.Styles{
+			Highlight: lightBlueForeground,
+			Muted:     darkGrayForeground,
+			Border: lipgloss.NewStyle().
+				Padding(0, 1, 1, 1).
+				Margin(0, 0, 0, 2).
+				BorderForeground(darkGray).
+				Border(lipgloss.RoundedBorder()),
+		}),
+		multiselect.WithPostFunc(func(s []int, model multiselect.Model) (tea.Model, tea.Cmd) {
+			selection = s
+			return model, tea.Quit
+		}),
+	)
+
+	if _, err = tea.NewProgram(m).Run(); err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	if len(selection) == 0 {
+		return nil
+	}
+
+	selected := make([]string, 0, len(selection))
+	for _, k := range selection {
+		selected = append(selected, names[k])
+	}
+
+	if content, err = appendApplications(content, selected, credentialsProfile); err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	if err = os.WriteFile(configPath, content, 0600); err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	fmt.Printf("Added %d applications to %s. They are commented out, set the package_source, artefact_paths or build of each application and uncomment it to scan it.\n", len(selected), configPath)
+
+	return nil
+}
+
+func editConfig(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	m, err := NewConfigureTask(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	if _, err = tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	return nil
+}
+
+func showConfig(cCtx *cli.Context) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	config, err := ShowConfig(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	fmt.Print(config)
+
+	return nil
+}
+
+func migrateConfig(cCtx *cli.Context) error {
+	configPath := cCtx.String("file")
+	if configPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			fmt.Print(renderErrors(err))
+			return err
+		}
+
+		configPath = filepath.Join(homeDir, ".veracode", "verapack", "config.yaml")
+	}
+
+	backupPath, changes, err := MigrateConfigFile(configPath, cCtx.Bool("dry-run"))
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	for _, change := range changes {
+		fmt.Printf("- %s\n", change)
+	}
+
+	switch {
+	case cCtx.Bool("dry-run"):
+		fmt.Printf("Dry run, %s was not changed.\n", configPath)
+	case backupPath == "":
+		fmt.Printf("%s is up to date.\n", configPath)
+	default:
+		fmt.Printf("Migrated %s to config_version %d. The original config file was saved to %s.\n", configPath, currentConfigVersion(), backupPath)
+	}
+
+	return nil
+}
+
+func printSchema(cCtx *cli.Context) error {
+	schema, err := GenerateSchema()
+	if err != nil {
+		fmt.Print(renderErrors(err))
+		return err
+	}
+
+	fmt.Println(string(schema))
+
+	return nil
+}
+
+// profileFlag returns the flag that selects the credentials profile for the credentials sub-commands.
+func profileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "Name of the profile in the credentials file. If omitted, the default profile is used",
+	}
+}
+
+// checkCredentialExpiry runs [CheckCredentialExpiry] before the scans start and prints the result.
+func checkCredentialExpiry(clients Clients, c Config, homeDir string) error {
+	for _, client := range clients.Sorted() {
+		msg, err := CheckCredentialExpiry(context.Background(), client, c, homeDir)
+		if err != nil {
+			fmt.Print(renderErrors(err))
+			return err
+		}
+
+		if msg != "" {
+			fmt.Print(renderNotice("Credentials", msg))
+		}
+	}
+
+	return nil
+}
+
 func RemoveBadApps(c *Config, badApps []*Options) {
 	newApplications := make([]Options, 0, len(c.Applications)-len(badApps))
 
@@ -9,7 +9,6 @@ import (
 	"math/rand/v2"
 	"net/http"
 	"net/http/cookiejar"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -160,7 +159,7 @@ func Sandbox_ui(cCtx *cli.Context) error {
 					RemoveBadApps(&c, badApps)
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
+						appsToSandboxOptions(c.Applications, nil),
 						client,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
@@ -198,7 +197,7 @@ func Sandbox_ui(cCtx *cli.Context) error {
 	} else {
 		// All apps are correct.
 		m = sand.NewModel(
-			appsToSandboxOptions(c.Applications),
+			appsToSandboxOptions(c.Applications, nil),
 			client,
 			ctx,
 			sand.WithSpinner(defaultSpinnerOpts...),
@@ -225,13 +224,14 @@ func Sandbox_ui(cCtx *cli.Context) error {
 
 	go func() {
 		<-startChan
+		shared := groupSharedClones(c.Applications)
 		for k, app := range c.Applications {
 			go func() {
 				if app.ScanType == ScanTypePromote {
 					// TODO
 					// promoteSandbox(client, ctx, app, k, p)
 				} else {
-					packageAndUploadApplication_ui(app, k, p, client, ctx)
+					packageAndUploadApplication_ui(app, k, p, client, shared[k] != nil, ctx)
 				}
 			}()
 		}
@@ -252,7 +252,16 @@ func Sandbox_ui(cCtx *cli.Context) error {
 	return nil
 }
 
-func packageAndUploadApplication_ui(options Options, appId int, reporter reporter, client *veracode.Client, ctx context.Context) {
+func packageAndUploadApplication_ui(options Options, appId int, reporter reporter, client *veracode.Client, hasSharedClone bool, ctx context.Context) {
+	// Clone
+	if options.PackageSource != "" && hasSharedClone {
+		reporter.Send(reportcard.TaskResultMsg{
+			Status: reportcard.Success,
+			Index:  appId,
+			Output: "Checked out commit: 8823f61a4e2b1c0d9f3e7a6b5c4d3e2f1a0b9c8d",
+		})
+	}
+
 	// Package
 	if options.PackageSource != "" {
 		if appId > 1 {
@@ -307,6 +316,15 @@ Total time taken to complete command: 46.724s`,
 		time.Sleep(time.Duration(rand.IntN(3)) * time.Second)
 	}
 
+	// Inspect
+	reporter.Send(reportcard.TaskResultMsg{
+		Status: reportcard.Success,
+		Index:  appId,
+		Output: `Total: 2 files, 0.1 MB
+  .zip: 2
+`,
+	})
+
 	// Upload
 	if appId > 0 && rand.IntN(4)+1 == 1 {
 		reporter.Send(reportcard.TaskResultMsg{
@@ -507,7 +525,7 @@ func Promote_ui(cCtx *cli.Context) error {
 					RemoveBadApps(&c, badApps)
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
+						appsToSandboxOptions(c.Applications, nil),
 						client,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
@@ -544,7 +562,7 @@ func Promote_ui(cCtx *cli.Context) error {
 					}
 
 					m := sand.NewModel(
-						appsToSandboxOptions(c.Applications),
+						appsToSandboxOptions(c.Applications, nil),
 						client,
 						ctx,
 						sand.WithSpinner(defaultSpinnerOpts...),
@@ -593,9 +611,9 @@ func Promote_ui(cCtx *cli.Context) error {
 			singleselect.WithPostFunc(afterFunc),
 		)
 	} else {
-		// m = sand.NewModel(appsToSandboxOptions(c.Applications), client, ctx)
+		// m = sand.NewModel(appsToSandboxOptions(c.Applications, nil), client, ctx)
 		m = sand.NewModel(
-			appsToSandboxOptions(c.Applications),
+			appsToSandboxOptions(c.Applications, nil),
 			client,
 			ctx,
 			sand.WithSpinner(defaultSpinnerOpts...),
@@ -622,12 +640,13 @@ func Promote_ui(cCtx *cli.Context) error {
 
 	go func() {
 		<-startChan
+		shared := groupSharedClones(c.Applications)
 		for k, app := range c.Applications {
 			go func() {
 				if app.ScanType == ScanTypePromote {
 					promoteSandbox(client, ctx, app, k, p)
 				} else {
-					packageAndUploadApplication_ui(app, k, p, client, ctx)
+					packageAndUploadApplication_ui(app, k, p, client, shared[k] != nil, ctx)
 				}
 			}()
 		}
@@ -680,9 +699,10 @@ func Policy_ui(cCtx *cli.Context) error {
 
 	p := tea.NewProgram(PrepareReportCard(c))
 
+	shared := groupSharedClones(c.Applications)
 	for k, app := range c.Applications {
 		go func() {
-			packageAndUploadApplication_ui(app, k, p, client, ctx)
+			packageAndUploadApplication_ui(app, k, p, client, shared[k] != nil, ctx)
 		}()
 	}
 
@@ -715,7 +735,7 @@ func RefreshCredentials_ui(cCtx *cli.Context) error {
 		return err
 	}
 
-	p := tea.NewProgram(NewCredentialsRefreshModel(client, ""))
+	p := tea.NewProgram(NewCredentialsRefreshModel(client, "", ""))
 	if _, err := p.Run(); err != nil {
 		return err
 	}
@@ -723,7 +743,7 @@ func RefreshCredentials_ui(cCtx *cli.Context) error {
 }
 
 func ConfigureCredentials_ui(cCtx *cli.Context) error {
-	p := tea.NewProgram(NewCredentialsConfigureModel(NewCredentialsTask(func() (string, string, error) { return "", "", nil }), ""))
+	p := tea.NewProgram(NewCredentialsConfigureModel(NewCredentialsTask(func() (string, string, error) { return "", "", nil }), "", ""))
 	if _, err := p.Run(); err != nil {
 		return err
 	}
@@ -803,7 +823,7 @@ func loadVersions(argPath string) versions {
 
 	v.UploaderLatestVersionFunc = func() (string, error) {
 		if v.UploaderLatestVersion == "" {
-			return GetLatestUploaderVersion(httpClient)
+			return GetLatestUploaderVersion(httpClient, Mirror{})
 		} else {
 			time.Sleep(600 * time.Millisecond)
 			return v.UploaderLatestVersion, nil
@@ -812,8 +832,7 @@ func loadVersions(argPath string) versions {
 
 	v.PackagerLatestVersionFunc = func() (string, error) {
 		if v.PackagerLatestVersion == "" {
-			baseURL, _ := url.Parse("https://tools.veracode.com/veracode-cli")
-			return GetLatestPackagerVersion(httpClient, baseURL)
+			return GetLatestPackagerVersion(httpClient, Mirror{})
 		} else {
 			time.Sleep(600 * time.Millisecond)
 			return v.PackagerLatestVersion, nil
@@ -0,0 +1,428 @@
+package verapack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	errProfileSync = errors.New("one or more application profiles could not be synced")
+
+	// businessCriticalities maps the values of [AppProfileOptions].BusinessCriticality to the values of the Applications API.
+	businessCriticalities = map[string]veracode.BusinessCriticality{
+		"VeryHigh": veracode.VeryHigh,
+		"High":     veracode.High,
+		"Medium":   veracode.Medium,
+		"Low":      veracode.Low,
+		"VeryLow":  veracode.VeryLow,
+	}
+
+	profileCreate   = lipgloss.NewStyle().Foreground(green)
+	profileUpdate   = lipgloss.NewStyle().Foreground(orange)
+	profileError    = redForeground
+	profileUpToDate = darkGrayForeground
+)
+
+// AppProfileOptions are the settings of the application profile that are provisioned by the profiles sync
+// command. Settings that are omitted are not changed on the platform.
+type AppProfileOptions struct {
+	// Business criticality of the application profile. It is required to create the profile.
+	BusinessCriticality string `yaml:"business_criticality" validate:"omitempty,oneof=VeryHigh High Medium Low VeryLow"`
+	// Name of the policy that is assigned to the application profile.
+	Policy string `yaml:"policy"`
+	// Names of the teams that are assigned to the application profile.
+	Teams []string `yaml:"teams" validate:"omitempty,dive,required"`
+	// Name of the business unit of the application profile.
+	BusinessUnit string `yaml:"business_unit"`
+	// Description of the application profile.
+	Description string `yaml:"description"`
+	// Tags of the application profile.
+	Tags []string `yaml:"tags" validate:"omitempty,dive,required,excludesall=0x2C"`
+	// Values of the custom fields of the application profile, by the name of the custom field. Custom fields
+	// that are omitted are not changed.
+	CustomFields map[string]string `yaml:"custom_fields" validate:"omitempty,dive,keys,required,endkeys"`
+}
+
+// appProfile returns the profile block of the application. The teams and business criticality of the
+// application are used if they are not set in the profile block, so that both the upload and the profiles
+// sync command use the same settings.
+func (o Options) appProfile() AppProfileOptions {
+	var p AppProfileOptions
+	if o.Profile != nil {
+		p = *o.Profile
+	}
+
+	if p.Teams == nil {
+		p.Teams = o.Teams
+	}
+
+	if p.BusinessCriticality == "" {
+		p.BusinessCriticality = o.Criticality
+	}
+
+	return p
+}
+
+// profileChange is a setting of the application profile that differs from its profile block.
+type profileChange struct {
+	Field   string // Name of the setting in the profile block.
+	Current string // Empty when the application profile does not exist.
+	Desired string
+}
+
+// diffProfile returns the settings of the application profile that differ from the profile block. If the
+// application profile does not exist, all of the settings in the profile block are returned.
+func diffProfile(current *veracode.Application, p AppProfileOptions) []profileChange {
+	var profile veracode.ApplicationProfile
+	if current != nil {
+		profile = current.Profile
+	}
+
+	var changes []profileChange
+
+	add := func(field, current, desired string) {
+		if !strings.EqualFold(current, desired) {
+			changes = append(changes, profileChange{Field: field, Current: current, Desired: desired})
+		}
+	}
+
+	if p.BusinessCriticality != "" {
+		add("business_criticality", string(profile.BusinessCriticality), string(businessCriticalities[p.BusinessCriticality]))
+	}
+
+	if p.Policy != "" {
+		var policy string
+		if len(profile.Policies) > 0 {
+			policy = profile.Policies[0].Name
+		}
+
+		add("policy", policy, p.Policy)
+	}
+
+	if p.Teams != nil {
+		teams := make([]string, 0, len(profile.Teams))
+		for _, team := range profile.Teams {
+			teams = append(teams, team.TeamName)
+		}
+
+		add("teams", sortedList(teams), sortedList(p.Teams))
+	}
+
+	if p.BusinessUnit != "" {
+		var bu string
+		if profile.BusinessUnit != nil {
+			bu = profile.BusinessUnit.Name
+		}
+
+		add("business_unit", bu, p.BusinessUnit)
+	}
+
+	if p.Description != "" && profile.Description != p.Description {
+		changes = append(changes, profileChange{Field: "description", Current: profile.Description, Desired: p.Description})
+	}
+
+	if p.Tags != nil {
+		var tags []string
+		if profile.Tags != "" {
+			tags = strings.Split(profile.Tags, ",")
+		}
+
+		add("tags", sortedList(tags), sortedList(p.Tags))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(p.CustomFields)) {
+		var value string
+		for _, field := range profile.CustomFields {
+			if field.Name == name {
+				value = field.Value
+			}
+		}
+
+		if value != p.CustomFields[name] {
+			changes = append(changes, profileChange{Field: "custom_fields." + name, Current: value, Desired: p.CustomFields[name]})
+		}
+	}
+
+	return changes
+}
+
+// sortedList returns the trimmed values sorted and joined with commas, so that lists can be compared
+// regardless of their order.
+func sortedList(values []string) string {
+	r := make([]string, len(values))
+	for k, v := range values {
+		r[k] = strings.TrimSpace(v)
+	}
+
+	slices.SortFunc(r, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+
+	return strings.Join(r, ",")
+}
+
+// profileResolver resolves the names of policies, teams and business units to their identifiers. The
+// identifiers are cached, because they are shared by the applications of a credentials profile.
+type profileResolver struct {
+	client        *veracode.Client
+	policies      map[string]string
+	teams         map[string]string
+	businessUnits map[string]string
+}
+
+func newProfileResolver(client *veracode.Client) *profileResolver {
+	return &profileResolver{
+		client:        client,
+		policies:      make(map[string]string),
+		teams:         make(map[string]string),
+		businessUnits: make(map[string]string),
+	}
+}
+
+func (r *profileResolver) policy(ctx context.Context, name string) (string, error) {
+	if guid, ok := r.policies[strings.ToLower(name)]; ok {
+		return guid, nil
+	}
+
+	policies, _, err := r.client.Policy.ListPolicies(ctx, veracode.ListPolicyOptions{Name: name, NameExact: "true"})
+	if err != nil {
+		return "", err
+	}
+
+	for _, policy := range policies {
+		if strings.EqualFold(policy.Name, name) {
+			r.policies[strings.ToLower(name)] = policy.Guid
+			return policy.Guid, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a policy with name: '%s'", name)
+}
+
+func (r *profileResolver) team(ctx context.Context, name string) (string, error) {
+	if guid, ok := r.teams[strings.ToLower(name)]; ok {
+		return guid, nil
+	}
+
+	all := true
+
+	teams, _, err := r.client.Identity.ListTeams(ctx, veracode.ListTeamOptions{TeamName: name, AllForOrg: &all})
+	if err != nil {
+		return "", err
+	}
+
+	for _, team := range teams {
+		if strings.EqualFold(team.TeamName, name) {
+			r.teams[strings.ToLower(name)] = team.TeamId
+			return team.TeamId, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a team with name: '%s'", name)
+}
+
+func (r *profileResolver) businessUnit(ctx context.Context, name string) (string, error) {
+	if guid, ok := r.businessUnits[strings.ToLower(name)]; ok {
+		return guid, nil
+	}
+
+	bus, _, err := r.client.Identity.ListBusinessUnits(ctx, veracode.ListBuOptions{SearchTerm: name})
+	if err != nil {
+		return "", err
+	}
+
+	for _, bu := range bus {
+		if strings.EqualFold(bu.BuName, name) {
+			r.businessUnits[strings.ToLower(name)] = bu.BuId
+			return bu.BuId, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a business unit with name: '%s'", name)
+}
+
+// apply sets the changed settings of the profile block on the application profile.
+func (r *profileResolver) apply(ctx context.Context, app *veracode.Application, p AppProfileOptions, changes []profileChange) error {
+	for _, change := range changes {
+		switch change.Field {
+		case "business_criticality":
+			app.Profile.BusinessCriticality = businessCriticalities[p.BusinessCriticality]
+
+		case "policy":
+			guid, err := r.policy(ctx, p.Policy)
+			if err != nil {
+				return err
+			}
+
+			app.Profile.Policies = []veracode.ApplicationPolicy{{Guid: guid}}
+
+		case "teams":
+			app.Profile.Teams = make([]veracode.ApplicationTeam, 0, len(p.Teams))
+
+			for _, name := range p.Teams {
+				guid, err := r.team(ctx, name)
+				if err != nil {
+					return err
+				}
+
+				app.Profile.Teams = append(app.Profile.Teams, veracode.ApplicationTeam{Guid: guid})
+			}
+
+		case "business_unit":
+			guid, err := r.businessUnit(ctx, p.BusinessUnit)
+			if err != nil {
+				return err
+			}
+
+			app.Profile.BusinessUnit = &veracode.ApplicationBusinessUnit{Guid: guid}
+
+		case "description":
+			app.Profile.Description = p.Description
+
+		case "tags":
+			app.Profile.Tags = strings.Join(p.Tags, ",")
+
+		default:
+			name := strings.TrimPrefix(change.Field, "custom_fields.")
+
+			k := slices.IndexFunc(app.Profile.CustomFields, func(field veracode.CustomField) bool { return field.Name == name })
+			if k == -1 {
+				app.Profile.CustomFields = append(app.Profile.CustomFields, veracode.CustomField{Name: name, Value: change.Desired})
+			} else {
+				app.Profile.CustomFields[k].Value = change.Desired
+			}
+		}
+	}
+
+	return nil
+}
+
+// findApplication returns the application profile with the name, or nil if it does not exist.
+func findApplication(ctx context.Context, client *veracode.Client, name string) (*veracode.Application, error) {
+	apps, _, err := client.Application.ListApplications(ctx, veracode.ListApplicationOptions{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, app := range apps {
+		if strings.EqualFold(app.Profile.Name, name) {
+			return &app, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// syncProfile creates the application profile if it does not exist, or updates the settings that differ
+// from its profile block. If dryRun is true, the application profile is not changed. It returns the
+// changes and whether the application profile was created.
+func syncProfile(ctx context.Context, r *profileResolver, name string, p AppProfileOptions, dryRun bool) ([]profileChange, bool, error) {
+	current, err := findApplication(ctx, r.client, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changes := diffProfile(current, p)
+
+	if current == nil && p.BusinessCriticality == "" {
+		return changes, true, errors.New("business_criticality is required to create the application profile")
+	}
+
+	if dryRun || len(changes) == 0 {
+		return changes, current == nil, nil
+	}
+
+	if current == nil {
+		app := veracode.Application{Profile: veracode.ApplicationProfile{Name: name}}
+
+		if err = r.apply(ctx, &app, p, changes); err != nil {
+			return changes, true, err
+		}
+
+		_, _, err = r.client.Application.CreateApplication(ctx, app)
+		return changes, true, err
+	}
+
+	// The Applications API requires all of the settings when the application profile is updated,
+	// therefore the changes are applied to the current settings.
+	app := *current
+
+	if err = r.apply(ctx, &app, p, changes); err != nil {
+		return changes, false, err
+	}
+
+	_, _, err = r.client.Application.UpdateApplication(ctx, app)
+	return changes, false, err
+}
+
+// SyncProfiles creates the missing application profiles of the applications that have a profile block and
+// updates the application profiles that differ from it. The changes are written to writer. If dryRun is
+// true, only the changes are written.
+func SyncProfiles(ctx context.Context, apps []Options, clients Clients, dryRun bool, writer io.Writer) error {
+	resolvers := make(map[*ProfileClient]*profileResolver)
+	seen := make(map[string]bool)
+
+	var failed bool
+
+	for _, app := range apps {
+		if app.Profile == nil {
+			continue
+		}
+
+		// Applications can be configured more than once, e.g. for policy and sandbox scans.
+		key := resolveProfileName(app.CredentialsProfile) + "/" + strings.ToLower(app.AppName)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		client := clients.For(app)
+		if resolvers[client] == nil {
+			resolvers[client] = newProfileResolver(client.Client)
+		}
+
+		changes, created, err := syncProfile(ctx, resolvers[client], app.AppName, app.appProfile(), dryRun)
+
+		switch {
+		case err != nil:
+			failed = true
+			fmt.Fprintf(writer, "%s %s: %s\n", profileError.Render("✗"), app.AppName, err)
+		case created:
+			fmt.Fprintf(writer, "%s %s %s\n", profileCreate.Render("+"), app.AppName, profileUpToDate.Render(syncLabel("create", dryRun)))
+		case len(changes) > 0:
+			fmt.Fprintf(writer, "%s %s %s\n", profileUpdate.Render("~"), app.AppName, profileUpToDate.Render(syncLabel("update", dryRun)))
+		default:
+			fmt.Fprintf(writer, "%s %s %s\n", profileUpToDate.Render("="), app.AppName, profileUpToDate.Render("(up to date)"))
+		}
+
+		for _, change := range changes {
+			if created {
+				fmt.Fprintf(writer, "    %s: %q\n", change.Field, change.Desired)
+			} else {
+				fmt.Fprintf(writer, "    %s: %q -> %q\n", change.Field, change.Current, change.Desired)
+			}
+		}
+	}
+
+	if failed {
+		return errProfileSync
+	}
+
+	return nil
+}
+
+// syncLabel returns the label of a profile sync action, e.g. "(will be created)" for a dry run or "(created)".
+func syncLabel(action string, dryRun bool) string {
+	if dryRun {
+		return fmt.Sprintf("(will be %sd)", action)
+	}
+
+	return fmt.Sprintf("(%sd)", action)
+}
@@ -0,0 +1,221 @@
+package verapack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestDiffProfile(t *testing.T) {
+	p := AppProfileOptions{
+		BusinessCriticality: "High",
+		Policy:              "Corporate Policy",
+		Teams:               []string{"Team B", "Team A"},
+		Tags:                []string{"java"},
+		CustomFields:        map[string]string{"Owner": "Platform"},
+	}
+
+	current := &veracode.Application{Profile: veracode.ApplicationProfile{
+		BusinessCriticality: veracode.High,
+		Policies:            []veracode.ApplicationPolicy{{Name: "Veracode Recommended Medium"}},
+		Teams:               []veracode.ApplicationTeam{{TeamName: "Team A"}, {TeamName: "team b"}},
+		Tags:                "java,legacy",
+		CustomFields:        []veracode.CustomField{{Name: "Owner", Value: "Platform"}},
+		Description:         "Not managed",
+	}}
+
+	var fields []string
+	for _, change := range diffProfile(current, p) {
+		fields = append(fields, change.Field)
+	}
+
+	if want := []string{"policy", "tags"}; !slices.Equal(fields, want) {
+		t.Errorf("diffProfile() changed fields = %v, want %v", fields, want)
+	}
+
+	if changes := diffProfile(nil, p); len(changes) != 5 {
+		t.Errorf("diffProfile() returned %d changes for a missing application profile, want 5", len(changes))
+	}
+}
+
+// profileHandler returns a handler of the Applications, Policies and Teams APIs with the existing
+// application profiles. The application profiles that are created or updated are sent to saved.
+func profileHandler(t *testing.T, apps []veracode.Application, saved chan<- veracode.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body any
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/appsec/v1/applications":
+			body = map[string]any{"_embedded": map[string]any{"applications": apps}}
+		case r.Method == http.MethodGet && r.URL.Path == "/appsec/v1/policies":
+			body = map[string]any{"_embedded": map[string]any{"policy_versions": []map[string]string{{"name": "Corporate Policy", "guid": "policy-guid"}}}}
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authn/v2/teams":
+			body = map[string]any{"_embedded": map[string]any{"teams": []map[string]string{{"team_name": r.URL.Query().Get("team_name"), "team_id": r.URL.Query().Get("team_name") + "-guid"}}}}
+		case r.Method == http.MethodPost && r.URL.Path == "/appsec/v1/applications",
+			r.Method == http.MethodPut && r.URL.Path == "/appsec/v1/applications/app-guid":
+			var app veracode.Application
+			b, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(b, &app); err != nil {
+				t.Errorf("%s %s body = %s, error = %v", r.Method, r.URL.Path, b, err)
+			}
+
+			saved <- app
+			body = app
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func TestSyncProfile(t *testing.T) {
+	p := AppProfileOptions{
+		BusinessCriticality: "High",
+		Policy:              "Corporate Policy",
+		Teams:               []string{"Team A"},
+		Description:         "Payments API",
+	}
+
+	existing := veracode.Application{Guid: "app-guid", Profile: veracode.ApplicationProfile{
+		Name:                "API",
+		BusinessCriticality: veracode.High,
+		Policies:            []veracode.ApplicationPolicy{{Name: "Corporate Policy", Guid: "policy-guid"}},
+		Teams:               []veracode.ApplicationTeam{{TeamName: "Team B", Guid: "Team B-guid"}},
+		Description:         "Payments API",
+		Tags:                "java",
+	}}
+
+	tests := []struct {
+		name        string
+		apps        []veracode.Application
+		dryRun      bool
+		wantCreated bool
+		wantFields  []string
+		want        func(t *testing.T, app veracode.Application)
+	}{
+		{
+			name:        "create",
+			wantCreated: true,
+			wantFields:  []string{"business_criticality", "policy", "teams", "description"},
+			want: func(t *testing.T, app veracode.Application) {
+				if app.Profile.Name != "API" || app.Profile.BusinessCriticality != veracode.High || app.Profile.Description != "Payments API" {
+					t.Errorf("CreateApplication() profile = %+v", app.Profile)
+				}
+
+				if len(app.Profile.Policies) != 1 || app.Profile.Policies[0].Guid != "policy-guid" {
+					t.Errorf("CreateApplication() policies = %+v, want policy-guid", app.Profile.Policies)
+				}
+
+				if len(app.Profile.Teams) != 1 || app.Profile.Teams[0].Guid != "Team A-guid" {
+					t.Errorf("CreateApplication() teams = %+v, want Team A-guid", app.Profile.Teams)
+				}
+			},
+		},
+		{
+			name:       "update",
+			apps:       []veracode.Application{existing},
+			wantFields: []string{"teams"},
+			want: func(t *testing.T, app veracode.Application) {
+				if len(app.Profile.Teams) != 1 || app.Profile.Teams[0].Guid != "Team A-guid" {
+					t.Errorf("UpdateApplication() teams = %+v, want Team A-guid", app.Profile.Teams)
+				}
+
+				// The settings that did not change are sent as well.
+				if app.Profile.Tags != "java" || len(app.Profile.Policies) != 1 || app.Profile.Description != "Payments API" {
+					t.Errorf("UpdateApplication() profile = %+v, want the current settings", app.Profile)
+				}
+			},
+		},
+		{name: "dry run", apps: []veracode.Application{existing}, dryRun: true, wantFields: []string{"teams"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := make(chan veracode.Application, 1)
+			r := newProfileResolver(newTestClient(t, profileHandler(t, tt.apps, saved)))
+
+			changes, created, err := syncProfile(context.Background(), r, "API", p, tt.dryRun)
+			if err != nil || created != tt.wantCreated {
+				t.Fatalf("syncProfile() = %v, %v, want %v", created, err, tt.wantCreated)
+			}
+
+			var fields []string
+			for _, change := range changes {
+				fields = append(fields, change.Field)
+			}
+
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("syncProfile() changed fields = %v, want %v", fields, tt.wantFields)
+			}
+
+			select {
+			case app := <-saved:
+				if tt.want == nil {
+					t.Errorf("syncProfile() saved the application profile: %+v", app.Profile)
+				} else {
+					tt.want(t, app)
+				}
+			default:
+				if tt.want != nil {
+					t.Error("syncProfile() did not save the application profile")
+				}
+			}
+		})
+	}
+}
+
+func TestSyncProfileError(t *testing.T) {
+	saved := make(chan veracode.Application, 1)
+	r := newProfileResolver(newTestClient(t, profileHandler(t, nil, saved)))
+
+	if _, created, err := syncProfile(context.Background(), r, "API", AppProfileOptions{Policy: "Corporate Policy"}, false); err == nil || !created {
+		t.Errorf("syncProfile() = %v, %v, want an error without business_criticality", created, err)
+	}
+
+	if _, _, err := syncProfile(context.Background(), r, "API", AppProfileOptions{BusinessCriticality: "Low", Policy: "Other Policy"}, false); err == nil {
+		t.Error("syncProfile() did not return an error for a policy that does not exist")
+	}
+
+	if len(saved) > 0 {
+		t.Error("syncProfile() saved the application profile after an error")
+	}
+}
+
+func TestAppProfile(t *testing.T) {
+	options := Options{Teams: []string{"Team A"}, Criticality: "Low", Profile: &AppProfileOptions{Policy: "Corporate Policy"}}
+
+	if p := options.appProfile(); !slices.Equal(p.Teams, options.Teams) || p.BusinessCriticality != "Low" || p.Policy != "Corporate Policy" {
+		t.Errorf("appProfile() = %+v, want the teams and criticality of the application", p)
+	}
+
+	options.Profile.Teams = []string{"Team B"}
+	options.Profile.BusinessCriticality = "High"
+
+	NewValidator()
+
+	var verrs validator.ValidationErrors
+	if err := validate.Struct(options); !errors.As(err, &verrs) {
+		t.Fatalf("Struct() error = %v", err)
+	}
+
+	var fields []string
+	for _, e := range verrs {
+		if e.Tag() == "excluded_with" {
+			fields = append(fields, e.Field())
+		}
+	}
+
+	if want := []string{"Teams", "Criticality"}; !slices.Equal(fields, want) {
+		t.Errorf("Struct() excluded_with errors = %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,212 @@
+package verapack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultBuildTimeout is the default number of seconds after which a build command is stopped.
+const defaultBuildTimeout = 3600
+
+var errBuildErr = errors.New("build error")
+
+// BuildOptions contains a custom build command, which is run instead of the auto-packager for applications
+// that the auto-packager can not package.
+type BuildOptions struct {
+	Command    string            `yaml:"command" validate:"required"`                         // Shell command that builds the application. It is run like the hooks, see [Hooks].
+	WorkingDir string            `yaml:"working_dir" validate:"omitempty,repo_path"`          // Directory in which the command is run, relative to the packaged directory, i.e. source_subdir if it is set.
+	Env        map[string]string `yaml:"env"`                                                 // Additional environment variables of the command.
+	Outputs    []string          `yaml:"outputs" validate:"required,gt=0,dive,required,glob"` // Glob patterns of the artefacts, relative to the working directory.
+	Timeout    int               `yaml:"timeout" validate:"gte=0"`                            // Number of seconds after which the command is stopped. The default value is 3600.
+}
+
+// timeout returns the duration after which the build command is stopped.
+func (b BuildOptions) timeout() time.Duration {
+	if b.Timeout == 0 {
+		return defaultBuildTimeout * time.Second
+	}
+
+	return time.Duration(b.Timeout) * time.Second
+}
+
+// BuildApplication runs the custom build command of the application in its source, which is a clone or a copy
+// of package_source, see [copySource]. It copies the artefacts that
+// match the output globs into outputDirPath. The command is stopped after its timeout. It returns the paths of the copied artefacts, the log output and
+// any error.
+//
+// env is appended to the environment of the command, followed by [BuildOptions].Env.
+//
+// writer can optionally be provided to write log output to an additional location.
+func BuildApplication(options Options, outputDirPath string, env []string, writer io.Writer) ([]string, string, error) {
+	build := options.Build
+	dir := filepath.Join(options.PackageSource, filepath.FromSlash(build.WorkingDir))
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		err = fmt.Errorf("build working_dir '%s' is not a directory in the source", build.WorkingDir)
+		return nil, err.Error(), err
+	}
+
+	for k, v := range build.Env {
+		env = append(env, k+"="+v)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), build.timeout())
+	defer cancel()
+
+	cmd := hookCommand(ctx, build.Command)
+	cmd.Dir = dir
+	cmd.Env = withEnv(env)
+	// Processes that were started by the build can keep the output pipes open after it was stopped.
+	cmd.WaitDelay = 5 * time.Second
+
+	var outBuffer bytes.Buffer
+
+	if writer != nil {
+		cmd.Stderr = io.MultiWriter(&outBuffer, writer)
+		cmd.Stdout = io.MultiWriter(&outBuffer, writer)
+	} else {
+		cmd.Stderr, cmd.Stdout = &outBuffer, &outBuffer
+	}
+
+	err := cmd.Run()
+
+	out := outBuffer.String()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, out + fmt.Sprintf("\nthe build did not complete within the timeout: %s", build.timeout()), errBuildErr
+	}
+
+	if err != nil {
+		return nil, err.Error() + "\n" + out, errBuildErr
+	}
+
+	artefacts, err := collectBuildOutputs(dir, build.Outputs, outputDirPath)
+	if err != nil {
+		return nil, out + err.Error(), err
+	}
+
+	var summary strings.Builder
+	for _, artefact := range artefacts {
+		fmt.Fprintf(&summary, "Collected artefact: %s\n", artefact)
+	}
+
+	if writer != nil {
+		fmt.Fprint(writer, summary.String())
+	}
+
+	return artefacts, out + summary.String(), nil
+}
+
+// collectBuildOutputs copies the files in dir that match any of the globs into outputDirPath. The paths of the
+// files relative to dir are kept, so that files with the same name in different directories do not collide.
+func collectBuildOutputs(dir string, globs []string, outputDirPath string) ([]string, error) {
+	var artefacts []string
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !matchAnyGlob(globs, rel) {
+			return nil
+		}
+
+		dst := filepath.Join(outputDirPath, rel)
+		if err = copyFile(p, dst); err != nil {
+			return err
+		}
+
+		artefacts = append(artefacts, dst)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(artefacts) == 0 {
+		return nil, fmt.Errorf("%w: none of the files in the build directory match the outputs: %s", errNoArtifacts, strings.Join(globs, ", "))
+	}
+
+	return artefacts, nil
+}
+
+// copySource copies the source directory src to dst, so that a build that writes into its source, e.g. into
+// node_modules or target, does not change the user's directory. Symbolic links are copied as links.
+func copySource(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		default:
+			return copyFile(p, target)
+		}
+	})
+}
+
+// copyFile copies the file src to dst with its permissions, and creates the parent directories of dst.
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
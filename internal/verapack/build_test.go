@@ -0,0 +1,99 @@
+package verapack
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildApplication(t *testing.T) {
+	source := t.TempDir()
+	out := t.TempDir()
+
+	command := "mkdir -p target/lib && echo $BUILD_NAME > target/app.war && touch target/lib/dep.jar target/app.txt"
+	if runtime.GOOS == "windows" {
+		command = "mkdir target\\lib && echo %BUILD_NAME%> target\\app.war && type nul > target\\lib\\dep.jar && type nul > target\\app.txt"
+	}
+
+	options := Options{
+		PackageSource: source,
+		Build: &BuildOptions{
+			Command: command,
+			Env:     map[string]string{"BUILD_NAME": "app"},
+			Outputs: []string{"target/*.war", "**/lib/*.jar"},
+		},
+	}
+
+	artefacts, log, err := BuildApplication(options, out, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildApplication() error = %v\n%s", err, log)
+	}
+
+	if len(artefacts) != 2 {
+		t.Fatalf("BuildApplication() = %v, want the war and jar files", artefacts)
+	}
+
+	for _, p := range []string{"target/app.war", "target/lib/dep.jar"} {
+		if _, err = os.Stat(filepath.Join(out, filepath.FromSlash(p))); err != nil {
+			t.Errorf("BuildApplication() did not collect %s: %v", p, err)
+		}
+	}
+
+	options.Build.Outputs = []string{"**/*.ear"}
+	if _, _, err = BuildApplication(options, t.TempDir(), nil, nil); err == nil {
+		t.Errorf("BuildApplication() expected an error if no files match the outputs")
+	}
+
+	options.Build = &BuildOptions{Command: "sleep 5", Outputs: []string{"*"}, Timeout: 1}
+	if runtime.GOOS == "windows" {
+		options.Build.Command = "ping -n 6 127.0.0.1 > nul"
+	}
+
+	if _, log, err = BuildApplication(options, t.TempDir(), nil, nil); err == nil || !strings.Contains(log, "timeout") {
+		t.Errorf("BuildApplication() error = %v, log = %q, want the build to be stopped after its timeout", err, log)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.jar", "app.jar", true},
+		{"*.jar", "lib/app.jar", false},
+		{"**/*.jar", "app.jar", true},
+		{"**/*.jar", "a/b/app.jar", true},
+		{"a/**/b/*.dll", "a/b/x.dll", true},
+		{"a/**/b/*.dll", "a/c/d/b/x.dll", true},
+		{"a/**/b/*.dll", "a/c/x.dll", false},
+		{"[", "[", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	src := newTestArtefacts(t, "gradlew", "src/main/App.java")
+	if err := os.Chmod(filepath.Join(src, "gradlew"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "source")
+	if err := copySource(src, dst); err != nil {
+		t.Fatalf("copySource() error = %v", err)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dst, "src", "main", "App.java")); err != nil || string(b) != "src/main/App.java" {
+		t.Errorf("copySource() did not copy src/main/App.java: %q, %v", b, err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dst, "gradlew")); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0755) {
+		t.Errorf("copySource() did not keep the permissions of gradlew: %v, %v", info, err)
+	}
+}
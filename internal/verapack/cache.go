@@ -0,0 +1,287 @@
+package verapack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// defaultCacheMaxSize is the default maximum size of the clone cache in MB.
+	defaultCacheMaxSize = 10240
+	// defaultCacheMaxAge is the default number of days after which an unused repository is removed from the clone cache.
+	defaultCacheMaxAge = 30
+)
+
+// CacheOptions contains the limits of the clone cache.
+type CacheOptions struct {
+	MaxSize int `yaml:"max_size" validate:"gte=0"` // Maximum size of the clone cache in MB.
+	MaxAge  int `yaml:"max_age" validate:"gte=0"`  // Number of days after which an unused repository is removed from the clone cache.
+}
+
+// setDefaults sets the default limits if they are not set.
+func (o *CacheOptions) setDefaults() {
+	if o.MaxSize == 0 {
+		o.MaxSize = defaultCacheMaxSize
+	}
+
+	if o.MaxAge == 0 {
+		o.MaxAge = defaultCacheMaxAge
+	}
+}
+
+// cacheLocks contains a mutex for every mirror in the clone cache, so that applications that are
+// packaged from the same repository do not update the mirror at the same time.
+var cacheLocks sync.Map
+
+// getCloneCacheDir returns the directory that contains the bare mirrors of the cached repositories.
+func getCloneCacheDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".veracode", "verapack", "cache", "git"), nil
+}
+
+// cloneCacheKey returns the name of the mirror directory of a repository. The name of the repository
+// is included to make the cache easier to inspect, and the hash ensures that it is unique.
+func cloneCacheKey(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	name := strings.TrimSuffix(path.Base(strings.ReplaceAll(url, "\\", "/")), ".git")
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+
+	return name + "-" + hex.EncodeToString(hash[:8]) + ".git"
+}
+
+// cloneFromCache clones the repository from a bare mirror in the clone cache.
+//
+// If the mirror does not exist yet, it is created. Otherwise, it is fetched incrementally. Afterwards, clone
+// is called with the URL of the mirror, to make the clone from the mirror in the same way as [CloneRepository]
+// does it without the cache.
+func cloneFromCache(gitPath string, env []string, options Options, writer io.Writer, clone func(source string) (GitInfo, string, error)) (GitInfo, string, error) {
+	mirrorPath, unlock, out, err := updateMirror(gitPath, env, options.PackageSource, writer)
+	if err != nil {
+		if unlock != nil {
+			unlock()
+		}
+
+		return GitInfo{}, out, err
+	}
+
+	defer unlock()
+
+	// The file:// protocol is required for a shallow clone from a local repository.
+	info, cloneOut, err := clone("file://" + filepath.ToSlash(mirrorPath))
+
+	return info, out + cloneOut, err
+}
+
+// updateMirror creates the bare mirror of the repository in the clone cache, or fetches it incrementally if
+// it exists, and returns its path. The mirror is locked until unlock is called, so that it is not updated while
+// it is being read. If the mirror could not be created, it is unlocked. If an existing mirror could not be
+// updated, its path is returned together with the error, and it stays locked, so that it can still be read.
+func updateMirror(gitPath string, env []string, url string, writer io.Writer) (mirrorPath string, unlock func(), out string, err error) {
+	cacheDir, err := getCloneCacheDir()
+	if err != nil {
+		return "", nil, err.Error(), err
+	}
+
+	mirrorPath = filepath.Join(cacheDir, cloneCacheKey(url))
+
+	lock, _ := cacheLocks.LoadOrStore(mirrorPath, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	unlock = lock.(*sync.Mutex).Unlock
+
+	if _, err = os.Stat(mirrorPath); errors.Is(err, fs.ErrNotExist) {
+		if err = os.MkdirAll(cacheDir, 0700); err != nil {
+			unlock()
+			return "", nil, err.Error(), err
+		}
+
+		out, err = runGitCommand(gitPath, env, writer, "clone", "--mirror", url, mirrorPath)
+		if err != nil {
+			// Remove the partial mirror, otherwise the next run will try to fetch into it.
+			os.RemoveAll(mirrorPath)
+			unlock()
+			return "", nil, out, err
+		}
+	} else {
+		out, err = runGitCommand(gitPath, env, writer, "-C", mirrorPath, "remote", "update", "--prune")
+		if err != nil {
+			return mirrorPath, unlock, out, err
+		}
+	}
+
+	// The modification time of the mirror is used to determine when it was last used.
+	now := time.Now()
+	os.Chtimes(mirrorPath, now, now)
+
+	return mirrorPath, unlock, out, nil
+}
+
+// cacheEntry is a single mirror in the clone cache.
+type cacheEntry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// listCacheEntries returns the mirrors in the clone cache, ordered from least to most recently used.
+func listCacheEntries(cacheDir string) ([]cacheEntry, error) {
+	dirEntries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries := make([]cacheEntry, 0, len(dirEntries))
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := cacheEntry{path: filepath.Join(cacheDir, dirEntry.Name()), lastUsed: info.ModTime()}
+
+		err = filepath.WalkDir(entry.path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				entry.size += info.Size()
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b cacheEntry) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+
+	return entries, nil
+}
+
+// PruneResult contains the outcome of [PruneCloneCache].
+type PruneResult struct {
+	Removed    int   // Number of repositories that were removed.
+	FreedBytes int64 // Size of the removed repositories.
+	Remaining  int   // Number of repositories that are still cached.
+	SizeBytes  int64 // Size of the repositories that are still cached.
+}
+
+// PruneCloneCache removes the mirrors that have not been used in the last MaxAge days, followed by the least recently
+// used mirrors until the cache is smaller than MaxSize. If all is true, all of the mirrors are removed.
+func PruneCloneCache(cacheDir string, options CacheOptions, all bool) (PruneResult, error) {
+	var r PruneResult
+
+	entries, err := listCacheEntries(cacheDir)
+	if err != nil {
+		return r, err
+	}
+
+	for _, entry := range entries {
+		r.SizeBytes += entry.size
+	}
+
+	maxSize := int64(options.MaxSize) * 1024 * 1024
+	oldest := time.Now().AddDate(0, 0, -options.MaxAge)
+
+	for _, entry := range entries {
+		if !all && entry.lastUsed.After(oldest) && r.SizeBytes <= maxSize {
+			r.Remaining++
+			continue
+		}
+
+		if err = os.RemoveAll(entry.path); err != nil {
+			return r, err
+		}
+
+		r.Removed++
+		r.FreedBytes += entry.size
+		r.SizeBytes -= entry.size
+	}
+
+	return r, nil
+}
+
+// LoadCacheOptions reads the cache section from the config file and sets the defaults.
+//
+// Like [LoadMirrors], the rest of the config file is not validated, so that the cache can be pruned
+// regardless of the state of the config. A missing config file is not an error.
+func LoadCacheOptions(configPath string) (CacheOptions, error) {
+	var c struct {
+		Cache CacheOptions `yaml:"cache"`
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return CacheOptions{}, err
+	}
+
+	if err = yaml.Unmarshal(content, &c); err != nil {
+		return CacheOptions{}, err
+	}
+
+	c.Cache.setDefaults()
+
+	return c.Cache, nil
+}
+
+// pruneCloneCacheAfterScan enforces the clone cache limits after the scans have completed. Errors are only
+// logged, because they should not fail the scan command.
+func pruneCloneCacheAfterScan(c Config) {
+	if !slices.ContainsFunc(c.Applications, func(o Options) bool { return o.CloneCache != nil && *o.CloneCache }) {
+		return
+	}
+
+	cacheDir, err := getCloneCacheDir()
+	if err != nil {
+		logToFile(fmt.Sprintf("could not prune the clone cache: %s", err))
+		return
+	}
+
+	if _, err = PruneCloneCache(cacheDir, c.Cache, false); err != nil {
+		logToFile(fmt.Sprintf("could not prune the clone cache: %s", err))
+	}
+}
+
+// formatBytes formats a number of bytes in MB.
+func formatBytes(b int64) string {
+	return fmt.Sprintf("%.1f MB", float64(b)/1024/1024)
+}
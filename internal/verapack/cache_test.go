@@ -0,0 +1,122 @@
+package verapack
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestRepository creates a git repository with a single commit that contains file.
+func newTestRepository(t *testing.T, file string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, file), []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "add " + file},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	return dir
+}
+
+func TestCloneFromCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	source := newTestRepository(t, "a.txt")
+	enabled := true
+	options := Options{PackageSource: source, Type: Repo, Branch: "main", CloneCache: &enabled}
+
+	// The first clone creates the mirror, the second one fetches into it.
+	for i := range 2 {
+		out := filepath.Join(t.TempDir(), "source")
+
+		if _, log, err := CloneRepository(options, out, nil); err != nil {
+			t.Fatalf("CloneRepository() run %d error = %v\n%s", i, err, log)
+		}
+
+		if _, err := os.Stat(filepath.Join(out, "a.txt")); err != nil {
+			t.Errorf("CloneRepository() run %d did not clone the file: %v", i, err)
+		}
+	}
+
+	cacheDir, err := getCloneCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := listCacheEntries(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 cached repository, got %d", len(entries))
+	}
+}
+
+func TestPruneCloneCache(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	for name, age := range map[string]time.Duration{"old.git": 60 * 24 * time.Hour, "new.git": 0} {
+		dir := filepath.Join(cacheDir, name)
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, "pack"), make([]byte, 1024*1024), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		lastUsed := time.Now().Add(-age)
+		if err := os.Chtimes(dir, lastUsed, lastUsed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := PruneCloneCache(cacheDir, CacheOptions{MaxSize: 10, MaxAge: 30}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Removed != 1 || r.Remaining != 1 {
+		t.Errorf("PruneCloneCache() = %+v, expected the old repository to be removed", r)
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, "new.git")); err != nil {
+		t.Errorf("PruneCloneCache() removed the recently used repository")
+	}
+
+	r, err = PruneCloneCache(cacheDir, CacheOptions{MaxSize: 10, MaxAge: 30}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Remaining != 0 {
+		t.Errorf("PruneCloneCache() with all = %+v, expected all repositories to be removed", r)
+	}
+}
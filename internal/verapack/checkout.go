@@ -0,0 +1,117 @@
+package verapack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// sparsePath returns the path in the format that git expects, i.e. relative to the root of the repository
+// and separated by forward slashes.
+func sparsePath(p string) string {
+	return strings.Trim(path.Clean(filepath.ToSlash(p)), "/")
+}
+
+// validateRepoPath is the validation function for validating that a path, e.g. a sparse path, is a relative
+// path inside the repository.
+func validateRepoPath(fl validator.FieldLevel) bool {
+	p := fl.Field().String()
+
+	if filepath.IsAbs(p) || filepath.VolumeName(p) != "" || strings.HasPrefix(filepath.ToSlash(p), "/") {
+		return false
+	}
+
+	p = sparsePath(p)
+
+	return p != "." && p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// setSparseCheckout restricts the checkout of the repository in outputDirPath to the directories in paths.
+// It has to be run before the commit is checked out.
+func setSparseCheckout(gitPath string, env []string, outputDirPath string, paths []string, writer io.Writer) (string, error) {
+	args := []string{"-C", outputDirPath, "sparse-checkout", "set", "--cone"}
+	for _, p := range paths {
+		args = append(args, sparsePath(p))
+	}
+
+	return runGitCommand(gitPath, env, writer, args...)
+}
+
+// completeCheckout initializes the submodules and downloads the LFS objects of the checkout in outputDirPath,
+// if the options require it, and verifies that the sparse paths exist in the checkout.
+//
+// source is the URL that the repository was cloned from, which is the mirror if the clone cache is used.
+func completeCheckout(gitPath string, env []string, options Options, source, outputDirPath string, writer io.Writer) (string, error) {
+	var log strings.Builder
+
+	run := func(args ...string) error {
+		out, err := runGitCommand(gitPath, env, writer, append([]string{"-C", outputDirPath}, args...)...)
+		log.WriteString(out)
+		return err
+	}
+
+	if (options.Submodules || options.LFS) && source != cloneSource(options) {
+		// Relative submodule URLs and the LFS server are resolved from the URL of origin, which is the
+		// mirror if the repository was cloned from the clone cache.
+		if err := run("remote", "set-url", "origin", cloneSource(options)); err != nil {
+			return log.String(), err
+		}
+	}
+
+	if options.Submodules {
+		// The commit of a submodule does not have to be the tip of a branch, which not every server allows to
+		// be fetched with a depth of 1. Fall back to the full history of the submodules if it fails.
+		if err := run("submodule", "update", "--init", "--recursive", "--depth", "1"); err != nil {
+			if err = run("submodule", "update", "--init", "--recursive"); err != nil {
+				return log.String(), err
+			}
+		}
+	}
+
+	if options.LFS {
+		if out, err := runGitCommand(gitPath, env, nil, "lfs", "version"); err != nil {
+			log.WriteString(out)
+			log.WriteString("git-lfs is required to download the LFS objects of the repository, but it is not installed\n")
+			return log.String(), err
+		}
+
+		args := []string{"lfs", "pull"}
+		if len(options.SparsePaths) > 0 {
+			paths := make([]string, 0, len(options.SparsePaths))
+			for _, p := range options.SparsePaths {
+				paths = append(paths, sparsePath(p))
+			}
+
+			args = append(args, "--include", strings.Join(paths, ","))
+		}
+
+		if err := run(args...); err != nil {
+			return log.String(), err
+		}
+	}
+
+	for _, p := range options.SparsePaths {
+		msg := fmt.Sprintf("Verified path: %s\n", p)
+
+		_, err := os.Stat(filepath.Join(outputDirPath, filepath.FromSlash(sparsePath(p))))
+		if err != nil {
+			msg = fmt.Sprintf("Sparse path '%s' does not exist in the checkout, it must be a directory in the repository\n", p)
+		}
+
+		if writer != nil {
+			fmt.Fprint(writer, msg)
+		}
+		log.WriteString(msg)
+
+		if err != nil {
+			return log.String(), errCloningErr
+		}
+	}
+
+	return log.String(), nil
+}
@@ -0,0 +1,90 @@
+package verapack
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloneRepositorySparse(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	source := newTestRepository(t, "root.txt")
+
+	for _, dir := range []string{"services/api", "services/web"} {
+		if err := os.MkdirAll(filepath.Join(source, filepath.FromSlash(dir)), 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(source, filepath.FromSlash(dir), "main.go"), []byte("package main"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "add services"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", source}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		ref         string
+		sparsePaths []string
+		wantErr     bool
+	}{
+		{name: "branch", sparsePaths: []string{"services/api"}},
+		{name: "ref", ref: "main", sparsePaths: []string{"./services/api/"}},
+		{name: "missing path", sparsePaths: []string{"services/missing"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "source")
+			options := Options{PackageSource: source, Type: Repo, Ref: tt.ref, SparsePaths: tt.sparsePaths}
+
+			_, log, err := CloneRepository(options, out, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CloneRepository() error = %v, wantErr %v\n%s", err, tt.wantErr, log)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !strings.Contains(log, "Verified path: "+tt.sparsePaths[0]) {
+				t.Errorf("CloneRepository() log does not verify the sparse path:\n%s", log)
+			}
+
+			if _, err := os.Stat(filepath.Join(out, "services", "api", "main.go")); err != nil {
+				t.Errorf("CloneRepository() did not check out the sparse path: %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(out, "services", "web")); err == nil {
+				t.Errorf("CloneRepository() checked out a directory outside of the sparse paths")
+			}
+		})
+	}
+}
+
+func TestValidateRepoPath(t *testing.T) {
+	NewValidator()
+
+	for path, valid := range map[string]bool{
+		"services/api":  true,
+		"./services":    true,
+		"/services":     false,
+		"../other-repo": false,
+		".":             false,
+	} {
+		if err := validate.Var(path, "repo_path"); (err == nil) != valid {
+			t.Errorf("validate repo path %s: error = %v, want valid %v", path, err, valid)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package verapack
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/DanCreative/veracode-go/veracode"
+)
+
+const (
+	// defaultCredentialsProfile is the name of the profile in the credentials file that is used when an
+	// application does not set [Options].CredentialsProfile.
+	defaultCredentialsProfile = "default"
+
+	RegionCommercial = "commercial"
+	RegionEuropean   = "european"
+	RegionFederal    = "federal"
+)
+
+// regionCharacters maps the values of [Options].Region to the character in the API key ID prefix that
+// identifies the region. API key IDs without a prefix belong to the commercial region.
+var regionCharacters = map[string]string{
+	RegionCommercial: "g",
+	RegionEuropean:   "e",
+	RegionFederal:    "f",
+}
+
+// ProfileClient is a Veracode client for a single credentials profile.
+//
+// It keeps a copy of the profile's credentials, because the Veracode tools that verapack runs as child
+// processes need to receive the same credentials as the client.
+type ProfileClient struct {
+	*veracode.Client
+	Profile      string // Empty for the default profile or when the profile was not set in the config.
+	apiKeyId     string
+	apiKeySecret string
+}
+
+// Env returns the environment variables that pass the profile's credentials to the Veracode tools.
+func (c *ProfileClient) Env() []string {
+	env := []string{
+		"VERACODE_API_KEY_ID=" + c.apiKeyId,
+		"VERACODE_API_KEY_SECRET=" + c.apiKeySecret,
+	}
+
+	if c.Profile != "" {
+		env = append(env, "VERACODE_API_PROFILE="+c.Profile)
+	}
+
+	return env
+}
+
+// UpdateCredentials updates the credentials of both the client and the child process environment.
+func (c *ProfileClient) UpdateCredentials(apiKeyId, apiKeySecret string) error {
+	if err := c.Client.UpdateCredentials(apiKeyId, apiKeySecret); err != nil {
+		return err
+	}
+
+	c.apiKeyId, c.apiKeySecret = apiKeyId, apiKeySecret
+
+	return nil
+}
+
+// Clients contains a client for every credentials profile used by the applications in the config,
+// keyed by the resolved profile name, see [resolveProfileName].
+type Clients map[string]*ProfileClient
+
+// For returns the client for the application's credentials profile.
+func (c Clients) For(options Options) *ProfileClient {
+	return c[resolveProfileName(options.CredentialsProfile)]
+}
+
+// Sorted returns the clients sorted by profile name, so that the output for multiple profiles is consistent.
+func (c Clients) Sorted() []*ProfileClient {
+	r := make([]*ProfileClient, 0, len(c))
+	for _, client := range c {
+		r = append(r, client)
+	}
+
+	slices.SortFunc(r, func(a, b *ProfileClient) int {
+		return strings.Compare(a.Profile, b.Profile)
+	})
+
+	return r
+}
+
+// NewVeracodeClients creates one client for every credentials profile used by the applications, and
+// verifies that the credentials belong to the region that the applications are configured for.
+func NewVeracodeClients(applications []Options) (Clients, error) {
+	clients := make(Clients)
+
+	for _, app := range applications {
+		// Applications that omit the profile and applications that name the profile it resolves to, e.g.
+		// the default profile, share a client.
+		name := resolveProfileName(app.CredentialsProfile)
+
+		client, ok := clients[name]
+		if !ok {
+			profile := app.CredentialsProfile
+			if name == defaultCredentialsProfile {
+				profile = ""
+			}
+
+			var err error
+
+			client, err = NewVeracodeProfileClient(profile)
+			if err != nil {
+				return nil, err
+			}
+
+			clients[name] = client
+		}
+
+		if err := checkCredentialsRegion(client.apiKeyId, app.Region); err != nil {
+			return nil, fmt.Errorf("application '%s': %w", app.AppName, err)
+		}
+	}
+
+	return clients, nil
+}
+
+// NewVeracodeProfileClient creates a client with the credentials of the provided profile. See [loadProfileCredentials].
+func NewVeracodeProfileClient(profile string) (*ProfileClient, error) {
+	key, secret, err := loadProfileCredentials(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newVeracodeClient(key, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProfileClient{
+		Client:       client,
+		Profile:      profile,
+		apiKeyId:     key,
+		apiKeySecret: secret,
+	}, nil
+}
+
+func newVeracodeClient(key, secret string) (*veracode.Client, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{
+		Jar: jar,
+	}
+
+	return veracode.NewClient(httpClient, key, secret)
+}
+
+// loadProfileCredentials loads the credentials of the named profile from the selected credentials store.
+// See [LoadCredentialsStore].
+//
+// If profile is empty, the profile is selected the same way as the other Veracode tools do it: using the
+// VERACODE_API_PROFILE environment variable, falling back to the default profile.
+func loadProfileCredentials(profile string) (string, string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	store, err := LoadCredentialsStore(homeDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	return store.get(profile)
+}
+
+// checkCredentialsRegion returns an error if region is set and the API key ID does not belong to it.
+//
+// The Veracode API region is determined by the prefix of the API key ID, therefore selecting a region
+// is done by selecting a credentials profile from that region's tenant.
+func checkCredentialsRegion(apiKeyId, region string) error {
+	if region == "" {
+		return nil
+	}
+
+	keyRegion, err := veracode.GetRegionFromCredentials(apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	if expected := veracode.Regions[regionCharacters[region]]; keyRegion["rest"] != expected["rest"] {
+		return fmt.Errorf("the credentials do not belong to the %s region (%s), please set 'credentials_profile' to a profile from that region", region, expected["rest"])
+	}
+
+	return nil
+}
+
+// withEnv returns the current process environment with env appended.
+func withEnv(env []string) []string {
+	return append(os.Environ(), env...)
+}
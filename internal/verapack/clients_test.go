@@ -0,0 +1,63 @@
+package verapack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckCredentialsRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKeyId string
+		region   string
+		wantErr  bool
+	}{
+		{name: "region not set", apiKeyId: "vera01ei-0123", region: "", wantErr: false},
+		{name: "key without prefix is commercial", apiKeyId: "0123", region: RegionCommercial, wantErr: false},
+		{name: "european key", apiKeyId: "vera01ei-0123", region: RegionEuropean, wantErr: false},
+		{name: "federal key", apiKeyId: "vera01fi-0123", region: RegionFederal, wantErr: false},
+		{name: "commercial key for european region", apiKeyId: "0123", region: RegionEuropean, wantErr: true},
+		{name: "european key for commercial region", apiKeyId: "vera01ei-0123", region: RegionCommercial, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkCredentialsRegion(tt.apiKeyId, tt.region); (err != nil) != tt.wantErr {
+				t.Errorf("checkCredentialsRegion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewVeracodeClients(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+	t.Setenv("VERACODE_API_PROFILE", "")
+	t.Setenv("VERAPACK_CREDENTIALS_STORE", CredentialsStoreFile)
+
+	if err := os.Mkdir(filepath.Join(homeDir, ".veracode"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for profile, apiKeyId := range map[string]string{"": "0123", "eu": "vera01ei-4567"} {
+		if err := setCredentialsFiles(homeDir, profile, apiKeyId, "0123456789abcdef"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apps := []Options{{AppName: "A"}, {AppName: "B", CredentialsProfile: defaultCredentialsProfile}, {AppName: "C", CredentialsProfile: "eu"}}
+
+	clients, err := NewVeracodeClients(apps)
+	if err != nil {
+		t.Fatalf("NewVeracodeClients() error = %v", err)
+	}
+
+	if len(clients) != 2 || clients.For(apps[0]) != clients.For(apps[1]) || clients.For(apps[0]).Profile != "" {
+		t.Errorf("NewVeracodeClients() = %v, want one client for the default profile", clients)
+	}
+
+	if client := clients.For(apps[2]); client == nil || client.apiKeyId != "vera01ei-4567" {
+		t.Errorf("For(eu) = %v, want the client of the eu profile", client)
+	}
+}
@@ -2,10 +2,13 @@ package verapack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/DanCreative/veracode-go/veracode"
 	"github.com/DanCreative/verapack/internal/components/reportcard"
@@ -13,6 +16,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// errPromotionCancelled is returned if a sandbox scan was not promoted, because it did not pass the policy rules.
+var errPromotionCancelled = errors.New("auto-promotion was cancelled")
+
 type reporter interface {
 	Send(msg tea.Msg)
 }
@@ -23,14 +29,30 @@ type reporter interface {
 //
 // UploadAndScanApplication requires ArtefactPaths to be set. Either it or PackageSource needs
 // to be set in the config. If PackageSource is set, PackageApplication will be run and set it.
-func packageAndUploadApplication(uploaderPath string, options Options, appId int, reporter reporter, client *veracode.Client, ctx context.Context) error {
-	var err error
+//
+// client is the client for the application's credentials profile. Its credentials are also passed to the
+// packager and the wrapper, because they might not be able to read them from the credentials store.
+//
+// shared is the clone that the application shares with other applications, if any, see [groupSharedClones].
+func packageAndUploadApplication(uploaderPath string, options Options, appId int, reporter reporter, client *ProfileClient, shared *sharedClone, ctx context.Context) (err error) {
 	sanitizer := runeutil.NewSanitizer()
 
+	// The shared clone is released as soon as the application has been packaged, so that it can be removed
+	// before the scans have completed.
+	release := func() {}
+	if shared != nil {
+		release = sync.OnceFunc(shared.release)
+	}
+
+	defer release()
+
 	// packageOutputBaseDirectory is the path to the individual apps' temp folder.
 	// It will contain a source clone folder and an artefact output folder.
 	var packageOutputBaseDirectory string
 
+	// env is updated after every task, so that the hooks get the current state of the application.
+	env := hookEnv{appName: options.AppName, scanType: options.ScanType, artefactPaths: options.ArtefactPaths}
+
 	logWriter, closeFunc, err := initializeLogWriter(options.AppName)
 	if err != nil {
 		reporter.Send(reportcard.TaskResultMsg{
@@ -38,11 +60,85 @@ func packageAndUploadApplication(uploaderPath string, options Options, appId int
 			Output: err.Error(),
 			Index:  appId,
 		})
+		onFailureHookTask(options, true, env, appId, reporter, io.Discard)
 		return err
 	}
 
 	defer closeFunc()
 
+	defer func() {
+		onFailureHookTask(options, err != nil, env, appId, reporter, logWriter)
+	}()
+
+	if options.GitAuth != nil {
+		// Errors are reported by the clone step.
+		token, _ := options.GitAuth.Token()
+		logWriter.AddSecret(token)
+	}
+
+	for _, secret := range options.Secrets {
+		logWriter.AddSecret(secret)
+	}
+
+	if options.PackageSource != "" && shared != nil {
+		fmt.Fprintf(logWriter, "BEGIN (%s)\n", columnClone)
+
+		var cloneOut, sourceDir string
+		options.Git, sourceDir, cloneOut, err = shared.clone()
+
+		// The clone is not written to the log while it runs, because it is shared by multiple applications.
+		fmt.Fprint(logWriter, cloneOut)
+		fmt.Fprintf(logWriter, "END (%s)\n", columnClone)
+
+		status := reportcard.Success
+		if err != nil {
+			status = reportcard.Failure
+		}
+
+		reporter.Send(reportcard.TaskResultMsg{
+			Status: status,
+			Output: string(sanitizer.Sanitize([]rune(logWriter.Redact(cloneOut)))),
+			Index:  appId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// The packager is told to use dir, like it is after a regular clone.
+		options.PackageSource = sourceDir
+		options.Type = Directory
+	}
+
+	// fingerprint identifies the scanned application, so that it can be skipped if it did not change.
+	var fingerprint, statePath string
+
+	// checkUnchangedTask returns whether the application did not change since its last successful scan, and the
+	// reason. It is run before the application is packaged, see [scanFingerprint].
+	checkUnchangedTask := func() (bool, string) {
+		if !options.SkipIfUnchanged {
+			return false, ""
+		}
+
+		var err error
+		statePath, err = getScanStatePath()
+		if err == nil {
+			fingerprint, err = scanFingerprint(options)
+		}
+
+		if err != nil {
+			fmt.Fprintf(logWriter, "skip_if_unchanged: the application is scanned, because its fingerprint could not be determined: %s\n", err)
+			return false, ""
+		}
+
+		unchanged, reason := checkUnchanged(ctx, client.Client, statePath, options, fingerprint)
+		if !unchanged {
+			fmt.Fprintf(logWriter, "skip_if_unchanged: %s\n", reason)
+		}
+
+		return unchanged, reason
+	}
+
 	if options.PackageSource != "" {
 		// Run the auto-packager
 
@@ -55,20 +151,24 @@ func packageAndUploadApplication(uploaderPath string, options Options, appId int
 				Output: err.Error(),
 				Index:  appId,
 			})
+			// The cleanup task completes the row, so that the on_failure hook is reported in the correct column.
+			cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
 			return err
 		}
 
+		env.workdir = packageOutputBaseDirectory
+
 		var cloneOut string
 
-		if options.Type == Repo || options.Branch != "" {
+		if shared == nil && (options.Type == Repo || options.Branch != "" || options.Ref != "") {
 			// Perform a shallow clone of a git repository.
 			// If the git repo is remote, this will always run.
-			// If the git repo is local, it will only be run if [Options].Branch is set.
-			cloneOut, err = CloneRepository(options, filepath.Join(packageOutputBaseDirectory, "source"), logWriter)
+			// If the git repo is local, it will only be run if [Options].Branch or [Options].Ref is set.
+			options.Git, cloneOut, err = CloneRepository(options, filepath.Join(packageOutputBaseDirectory, "source"), logWriter)
 			if err != nil {
 				reporter.Send(reportcard.TaskResultMsg{
 					Status: reportcard.Failure,
-					Output: string(sanitizer.Sanitize([]rune(cloneOut))),
+					Output: string(sanitizer.Sanitize([]rune(logWriter.Redact(cloneOut)))),
 					Index:  appId,
 				})
 				cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
@@ -81,11 +181,67 @@ func packageAndUploadApplication(uploaderPath string, options Options, appId int
 			options.Type = Directory
 		}
 
-		artefactPaths, out, err := PackageApplication(options, filepath.Join(packageOutputBaseDirectory, "out"), logWriter)
+		if unchanged, reason := checkUnchangedTask(); unchanged {
+			fmt.Fprintf(logWriter, "END (%s)\n", columnPackage)
+			return skipUnchangedTasks(options, packageOutputBaseDirectory, reason, appId, reporter, logWriter)
+		}
+
+		if options.Git.SHA == "" && options.Build != nil {
+			// A custom build writes into its source, therefore a source that was not cloned is copied, so that the
+			// build runs in a copy of the directory, like in a clone.
+			source := filepath.Join(packageOutputBaseDirectory, "source")
+			fmt.Fprintf(logWriter, "Copying %s to %s\n", options.PackageSource, source)
+
+			if err = copySource(options.PackageSource, source); err != nil {
+				fmt.Fprintf(logWriter, "%s\nEND (%s)\n", err, columnPackage)
+				reporter.Send(reportcard.TaskResultMsg{
+					Status: reportcard.Failure,
+					Output: err.Error(),
+					Index:  appId,
+				})
+				cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+				return err
+			}
+
+			options.PackageSource = source
+		}
+
+		options.PackageSource, err = packageSourceDir(options)
+		if err != nil {
+			fmt.Fprintf(logWriter, "%s\nEND (%s)\n", err, columnPackage)
+			reporter.Send(reportcard.TaskResultMsg{
+				Status: reportcard.Failure,
+				Output: err.Error(),
+				Index:  appId,
+			})
+			cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+			return err
+		}
+
+		env.sourceDir = options.PackageSource
+
+		if hasPrePackageHookTask(options) {
+			if err = runHookTask(columnPrePackage, options.Hooks.PrePackage, options.Hooks, env, appId, reporter, logWriter); err != nil {
+				cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+				return err
+			}
+		}
+
+		// A custom build replaces the auto-packager. The credentials are not passed to the build command,
+		// because it can run third-party code, e.g. package install scripts.
+		packageFunc, packageEnv := PackageApplication, client.Env()
+		if options.Build != nil {
+			packageFunc, packageEnv = BuildApplication, nil
+		}
+
+		artefactPaths, out, err := packageFunc(options, filepath.Join(packageOutputBaseDirectory, "out"), packageEnv, logWriter)
 		fmt.Fprintf(logWriter, "END (%s)\n", columnPackage)
 
+		// The output of a build can contain the interpolated values of its env.
+		out = logWriter.Redact(out)
+
 		if *options.Verbose {
-			out = cloneOut + out
+			out = logWriter.Redact(cloneOut) + out
 		}
 
 		if err != nil {
@@ -112,50 +268,167 @@ func packageAndUploadApplication(uploaderPath string, options Options, appId int
 		})
 
 		options.ArtefactPaths = artefactPaths
+		env.artefactPaths = artefactPaths
+
+		if hasPostPackageHookTask(options) {
+			if err = runHookTask(columnPostPackage, options.Hooks.PostPackage, options.Hooks, env, appId, reporter, logWriter); err != nil {
+				cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+				return err
+			}
+		}
+
+		release()
+	} else if unchanged, reason := checkUnchangedTask(); unchanged {
+		return skipUnchangedTasks(options, packageOutputBaseDirectory, reason, appId, reporter, logWriter)
+	}
+
+	// The filtered artefacts are staged in the workdir. Applications without a package source do not have a workdir,
+	// therefore one is created that is removed after the upload.
+	stageDir := filepath.Join(packageOutputBaseDirectory, "filtered")
+	if packageOutputBaseDirectory == "" && (len(options.Include) > 0 || len(options.Exclude) > 0) {
+		workdir, err := createAppPackagingOutputDir(options.AppName)
+		if err != nil {
+			fmt.Fprintf(logWriter, "BEGIN (%s)\n%s\nEND (%s)\n", columnInspect, err, columnInspect)
+			reporter.Send(reportcard.TaskResultMsg{
+				Status: reportcard.Failure,
+				Output: err.Error(),
+				Index:  appId,
+			})
+			return err
+		}
+
+		defer os.RemoveAll(workdir)
+		stageDir = filepath.Join(workdir, "filtered")
+	}
+
+	options.ArtefactPaths, err = inspectTask(options, stageDir, appId, reporter, logWriter)
+	if err != nil {
+		cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+		return err
 	}
 
+	env.artefactPaths = options.ArtefactPaths
+
 	options.UploaderFilePath = uploaderPath
 
-	out, err := UploadAndScanApplication(options, logWriter)
+	// The version is rendered after the clone step, so that it can contain the git metadata.
+	options.Version, err = RenderVersion(options)
 	if err != nil {
+		if hasPreUploadHookTask(options) {
+			// The hook is not run, because the failure is reported in the Upload column.
+			reporter.Send(reportcard.TaskResultMsg{Status: reportcard.Skip, Index: appId})
+		}
+
+		fmt.Fprintf(logWriter, "BEGIN (%s)\n%s\nEND (%s)\n", columnUpload, err, columnUpload)
 		reporter.Send(reportcard.TaskResultMsg{
 			Status: reportcard.Failure,
-			Output: string(sanitizer.Sanitize([]rune(out))),
+			Output: err.Error(),
 			Index:  appId,
 		})
 		cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
 		return err
 	}
 
+	env.version = options.Version
+
+	if hasPreUploadHookTask(options) {
+		if err = runHookTask(columnPreUpload, options.Hooks.PreUpload, options.Hooks, env, appId, reporter, logWriter); err != nil {
+			cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+			return err
+		}
+	}
+
+	// The wrapper can not upload a new build while a previous build is incomplete.
+	existingOut, err := handleExistingBuild(ctx, client.Client, options, logWriter)
+	if err != nil {
+		fmt.Fprintf(logWriter, "BEGIN (%s)\n%s\nEND (%s)\n", columnUpload, existingOut, columnUpload)
+		reporter.Send(reportcard.TaskResultMsg{
+			Status: reportcard.Failure,
+			Output: existingOut,
+			Index:  appId,
+		})
+		cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+		return err
+	}
+
+	out, err := UploadAndScanApplication(options, client.Env(), logWriter)
+	out = existingOut + out
+	if err != nil {
+		reporter.Send(reportcard.TaskResultMsg{
+			Status:       reportcard.Failure,
+			Output:       newUploadOutput(ctx, client.Client, options, string(sanitizer.Sanitize([]rune(out)))),
+			ViewportName: viewportPrescan,
+			Index:        appId,
+		})
+		cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+		return err
+	}
+
+	env.buildId = parseBuildId(out)
+
+	shouldAutoPromote := options.AutoPromote && options.ScanType == ScanTypeSandbox
+
+	// The scan is recorded once it succeeded, so that a failed scan is retried by the next run. If the result is
+	// not awaited, the scan succeeded once it is submitted.
+	recordScanTask := func() {
+		if fingerprint == "" {
+			return
+		}
+
+		if err := recordScan(statePath, options, scanRecord{Fingerprint: fingerprint, ScannedAt: time.Now()}); err != nil {
+			fmt.Fprintf(logWriter, "skip_if_unchanged: the scan could not be recorded: %s\n", err)
+		}
+	}
+
+	if !shouldAutoPromote && !options.WaitForResult {
+		recordScanTask()
+	}
+
 	reporter.Send(reportcard.TaskResultMsg{
-		Status: reportcard.Success,
-		Index:  appId,
-		Output: string(sanitizer.Sanitize([]rune(out))),
+		Status:       reportcard.Success,
+		Index:        appId,
+		Output:       newUploadOutput(ctx, client.Client, options, string(sanitizer.Sanitize([]rune(out)))),
+		ViewportName: viewportPrescan,
 	})
 
-	cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter)
+	if err = cleanupTask(options, packageOutputBaseDirectory, appId, reporter, logWriter); err != nil {
+		return err
+	}
 
-	shouldAutoPromote := options.AutoPromote && options.ScanType == ScanTypeSandbox
+	// The result is "submitted", unless the result of the scan is awaited.
+	env.result = "submitted"
 
 	if shouldAutoPromote {
-		err = autoPromoteTask(ctx, client, options, appId, reporter, logWriter)
+		res, err := autoPromoteTask(ctx, client.Client, options, appId, reporter, logWriter)
+		env.result = res.hookResult()
 		if err != nil {
 			return err
 		}
+
+		recordScanTask()
 	}
 
 	if options.WaitForResult && !shouldAutoPromote {
-		err = waitForResultTask(ctx, client, options, appId, reporter)
+		res, err := waitForResultTask(ctx, client.Client, options, appId, reporter)
 		if err != nil {
 			fmt.Fprintf(logWriter, "BEGIN (%s)\n%s\nEND (%s)\n", columnResult, err, columnResult)
 			return err
 		}
+
+		env.result = res.hookResult()
+		recordScanTask()
+	}
+
+	if hasPostScanHookTask(options) {
+		if err = runHookTask(columnPostScan, options.Hooks.PostScan, options.Hooks, env, appId, reporter, logWriter); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func cleanupTask(options Options, packageOutputBaseDirectory string, appId int, reporter reporter, writer io.Writer) {
+func cleanupTask(options Options, packageOutputBaseDirectory string, appId int, reporter reporter, writer io.Writer) error {
 	if *options.AutoCleanup && options.PackageSource != "" {
 		err := os.RemoveAll(packageOutputBaseDirectory)
 		if err != nil {
@@ -166,6 +439,7 @@ func cleanupTask(options Options, packageOutputBaseDirectory string, appId int,
 				Output: err.Error(),
 				Index:  appId,
 			})
+			return err
 		} else {
 			reporter.Send(reportcard.TaskResultMsg{
 				Status: reportcard.Success,
@@ -173,10 +447,12 @@ func cleanupTask(options Options, packageOutputBaseDirectory string, appId int,
 			})
 		}
 	}
+
+	return nil
 }
 
-func waitForResultTask(ctx context.Context, client *veracode.Client, options Options, appId int, reporter reporter) error {
-	result, out, err := WaitForResult(ctx, client, options, reporter)
+func waitForResultTask(ctx context.Context, client *veracode.Client, options Options, appId int, reporter reporter) (result, error) {
+	res, out, err := WaitForResult(ctx, client, options, reporter)
 	if err != nil {
 		reporter.Send(reportcard.TaskResultMsg{
 			Status: reportcard.Failure,
@@ -184,7 +460,7 @@ func waitForResultTask(ctx context.Context, client *veracode.Client, options Opt
 			Index:  appId,
 		})
 
-		return err
+		return res, err
 	}
 
 	// taskResult is re-used to send both the custom status for the Result column and the Policy column (if it is a policy scan)
@@ -193,18 +469,18 @@ func waitForResultTask(ctx context.Context, client *veracode.Client, options Opt
 		Index:  appId,
 	}
 
-	taskResult.CustomSuccessStatus = createCustomTaskStatusFromResult(result, false)
+	taskResult.CustomSuccessStatus = createCustomTaskStatusFromResult(res, false)
 	reporter.Send(taskResult)
 
 	if options.ScanType == ScanTypePolicy {
-		taskResult.CustomSuccessStatus = createCustomTaskStatusFromResult(result, true)
+		taskResult.CustomSuccessStatus = createCustomTaskStatusFromResult(res, true)
 		reporter.Send(taskResult)
 	}
 
-	return nil
+	return res, nil
 }
 
-func autoPromoteTask(ctx context.Context, client *veracode.Client, options Options, appId int, reporter reporter, writer io.Writer) error {
+func autoPromoteTask(ctx context.Context, client *veracode.Client, options Options, appId int, reporter reporter, writer io.Writer) (result, error) {
 	res, out, err := WaitForResult(ctx, client, options, reporter)
 	if err != nil {
 		fmt.Fprintf(writer, "BEGIN (%s)\n%s\nEND (%s)\n", columnResult, err, columnResult)
@@ -214,7 +490,7 @@ func autoPromoteTask(ctx context.Context, client *veracode.Client, options Optio
 			Index:  appId,
 		})
 
-		return err
+		return res, err
 	}
 
 	// Result column
@@ -236,7 +512,7 @@ func autoPromoteTask(ctx context.Context, client *veracode.Client, options Optio
 				Index:  appId,
 				Output: err.Error(),
 			})
-			return err
+			return res, err
 		}
 
 		reporter.Send(reportcard.TaskResultMsg{
@@ -252,7 +528,7 @@ func autoPromoteTask(ctx context.Context, client *veracode.Client, options Optio
 			Output: "The application did not pass the policy rules. Therefore auto-promotion was cancelled.",
 		})
 
-		return nil
+		return res, errPromotionCancelled
 	}
 
 	// Policy column
@@ -264,7 +540,7 @@ func autoPromoteTask(ctx context.Context, client *veracode.Client, options Optio
 			Index:  appId,
 			Output: err.Error(),
 		})
-		return err
+		return res, err
 	}
 
 	res = result{PassedPolicy: summaryReport.PolicyRulesStatus == "Pass", PolicyStatus: summaryReport.PolicyComplianceStatus}
@@ -275,8 +551,7 @@ func autoPromoteTask(ctx context.Context, client *veracode.Client, options Optio
 		CustomSuccessStatus: createCustomTaskStatusFromResult(res, true),
 	})
 
-	return nil
-
+	return res, nil
 }
 
 func createCustomTaskStatusFromResult(result result, isPolicyStatus bool) reportcard.CustomTaskStatus {
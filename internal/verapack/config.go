@@ -2,6 +2,8 @@ package verapack
 
 import (
 	_ "embed"
+	"errors"
+	"fmt"
 	"net/url"
 	"os"
 	"regexp"
@@ -40,8 +42,8 @@ type Options struct {
 	CreateProfile *bool `yaml:"create_profile"`
 	// FilePath is a []string of the filepaths for the application's artefacts.
 	ArtefactPaths []string `yaml:"artefact_paths" validate:"required_without=PackageSource,omitempty,dive,file|dir"`
-	// Name or version of the build that you want to scan.
-	Version string `yaml:"version" validate:"required"`
+	// Name or version of the build that you want to scan. It can be a template, see [versionTemplateData].
+	Version string `yaml:"version" validate:"required,version_template,version_unique"`
 
 	SandboxName string `yaml:"sandbox_name"` // Name of the sandbox in which to run the scan. This is what the user will provide in the yaml file.
 	SandboxId   int    `yaml:"-"`            // ID of the sandbox in which to run the scan. Application will determine the sandbox id from the provided sandbox name.
@@ -53,6 +55,10 @@ type Options struct {
 	WaitForResult       bool `yaml:"wait_for_result"`       // Wait for the results of the scan.
 	ScanTimeout         int  `yaml:"scan_timeout"`          // Number of minutes to wait for the scan to complete and pass policy.
 	ScanPollingInterval int  `yaml:"scan_polling_interval"` // Interval, in seconds, to poll for the status of a running scan.
+	// defaultScanTimeout is true if ScanTimeout was not set in the config file, but by [setPostMergeDefaults].
+	defaultScanTimeout bool
+	// interpolateErr contains the errors of the references in the options, see [interpolateConfig].
+	interpolateErr error
 
 	// Packaging Options
 
@@ -65,13 +71,88 @@ type Options struct {
 
 	// Other options:
 
-	ScanType ScanType `yaml:"-"` // The type of scan to run. Can be either policy or sandbox at this stage.
-	Branch   string   `yaml:"branch"`
+	ScanType   ScanType `yaml:"-"` // The type of scan to run. Can be either policy or sandbox at this stage.
+	Branch     string   `yaml:"branch"`
+	CloneCache *bool    `yaml:"clone_cache"` // Clone remote repositories from a persistent mirror that is fetched incrementally, instead of cloning them from scratch.
+	// Ref is the branch, tag, full or abbreviated commit SHA, or "latest-tag" to scan. It can not be used with Branch.
+	Ref string  `yaml:"ref" validate:"excluded_with=Branch"`
+	Git GitInfo `yaml:"-"` // Metadata of the cloned commit. It is set after the clone step.
+	// Submodules initializes the submodules of the repository recursively after cloning it.
+	Submodules bool `yaml:"submodules"`
+	// LFS downloads the git LFS objects of the checkout after cloning it. git-lfs must be installed.
+	LFS bool `yaml:"lfs"`
+	// SparsePaths are the directories of the repository to check out. If omitted, the whole repository is checked out.
+	SparsePaths []string `yaml:"sparse_paths" validate:"omitempty,dive,required,repo_path"`
+	// SourceSubdir is the directory inside the source that is packaged. Applications that are packaged from
+	// the same repository share a single clone, see [groupSharedClones].
+	SourceSubdir string `yaml:"source_subdir" validate:"omitempty,repo_path"`
+	// Credentials that are used to clone the repository. If omitted, the credentials of the user's git are used.
+	GitAuth *GitAuth `yaml:"git_auth"`
+	// Shell commands that are run before or after the tasks of the application.
+	Hooks Hooks `yaml:"hooks"`
+	// Patterns of the names of the top-level modules that are scanned. The * wildcard matches 0 or more characters.
+	IncludeModules []string `yaml:"include_modules" validate:"omitempty,dive,required,excludesall=0x2C"`
+	// Patterns of the names of the top-level modules that are not scanned.
+	ExcludeModules []string `yaml:"exclude_modules" validate:"omitempty,dive,required,excludesall=0x2C"`
+	// Scan all of the top-level modules that do not have fatal errors, instead of the modules that are selected by the platform.
+	ScanAllNonFatalTopLevelModules bool `yaml:"scan_all_non_fatal_top_level_modules" validate:"excluded_with=IncludeModules"`
+	// Teams that are assigned to the application profile, when it is created. It can not be used together
+	// with profile.teams, see [Options.appProfile].
+	Teams []string `yaml:"teams" validate:"omitempty,dive,required,excludesall=0x2C"`
+	// Business criticality of the application profile, when it is created. It can not be used together
+	// with profile.business_criticality.
+	Criticality string `yaml:"criticality" validate:"omitempty,oneof=VeryHigh High Medium Low VeryLow"`
+	// Lifecycle stage of the build.
+	LifecycleStage string `yaml:"lifecycle_stage" validate:"omitempty,lifecycle_stage"`
+	// Additional arguments of the API wrapper's UploadAndScan action, as pairs of a flag and its value.
+	WrapperArgs []string `yaml:"wrapper_args"`
+	// What happens if the application or sandbox has an incomplete build when a new build is uploaded. The default value is fail.
+	OnExistingBuild ExistingBuildAction `yaml:"on_existing_build" validate:"omitempty,oneof=fail wait delete"`
+	// Skip the scan if the application did not change since its last successful scan, unless its policy requires a new scan.
+	SkipIfUnchanged bool `yaml:"skip_if_unchanged"`
+	// Glob patterns of the files in the artefacts that are uploaded. If omitted, all of the files are uploaded.
+	Include []string `yaml:"include" validate:"omitempty,dive,required,glob"`
+	// Glob patterns of the files in the artefacts that are not uploaded.
+	Exclude []string `yaml:"exclude" validate:"omitempty,dive,required,glob"`
+	// Settings of the application profile that are provisioned by the profiles sync command. Its teams and
+	// business_criticality can not be used together with Teams and Criticality.
+	Profile *AppProfileOptions `yaml:"profile"`
+	// Custom build that is run instead of the auto-packager. It requires PackageSource to be set.
+	Build *BuildOptions `yaml:"build" validate:"omitempty,excluded_with=ArtefactPaths"`
+
+	// Credentials Options
+
+	// Name of the profile in the credentials file to use for the application. If omitted, the VERACODE_API_PROFILE environment variable or the default profile is used.
+	CredentialsProfile string `yaml:"credentials_profile"`
+	// Veracode region of the application's tenant. The credentials of CredentialsProfile must belong to it.
+	Region string `yaml:"region" validate:"omitempty,oneof=commercial european federal"`
+
+	// Secrets are the secret values that were interpolated into the options, see [interpolate]. They are redacted from the logs.
+	Secrets []string `yaml:"-"`
 }
 
 type Config struct {
+	// Version of the config file, see [MigrateConfig]. If omitted, the config file is version 1.
+	ConfigVersion int `yaml:"config_version" validate:"gte=0"`
+	// Other config files that are merged into the config file, see [LoadConfigFile].
+	Include      []Include `yaml:"include" validate:"dive"`
 	Default      Options   `yaml:"default" validate:"-"`
 	Applications []Options `yaml:"applications" validate:"required,gt=0,dive"`
+	Mirrors      Mirrors   `yaml:"mirrors"` // Download mirrors for the Veracode tools. Can be overridden with environment variables, see [LoadMirrors].
+
+	// Automatically re-generate the API credentials before scanning, if they expire within CredentialExpiryWarningDays.
+	AutoRefreshCredentials bool `yaml:"auto_refresh_credentials"`
+	// Number of days before the API credentials expire, in which the user will be warned (or the credentials refreshed) when scanning.
+	// 0 turns the warning off. It is nil until the defaults are set.
+	CredentialExpiryWarningDays *int `yaml:"credential_expiry_warning_days" validate:"gte=0"`
+	// Limits of the clone cache. See [Options].CloneCache.
+	Cache CacheOptions `yaml:"cache"`
+
+	// Where the API credentials are stored. Can be overridden with an environment variable, see [LoadCredentialsStore].
+	CredentialsStore string `yaml:"credentials_store" validate:"omitempty,oneof=file secret_store"`
+
+	// Secrets are the secret values that were interpolated into the config file, see [interpolate].
+	Secrets []string `yaml:"-"`
 }
 
 // NewConfig returns a new Config and sets all pointer values to avoid nil pointer errors downstream.
@@ -83,6 +164,7 @@ func NewConfig() Config {
 			CreateProfile: &b,
 			Verbose:       &b,
 			AutoCleanup:   &b,
+			CloneCache:    &b,
 
 			// Setting trust to true because when it is false, it requires user input and that is not
 			// supporter/required by this application.
@@ -95,13 +177,18 @@ func NewValidator() *validator.Validate {
 	validate = validator.New()
 
 	validate.RegisterStructValidation(optionsStructLevelValidation, Options{})
+	validate.RegisterValidation("version_template", validateVersionTemplate)
+	validate.RegisterValidation("version_unique", validateVersionUnique)
+	validate.RegisterValidation("repo_path", validateRepoPath)
+	validate.RegisterValidation("glob", validateGlob)
+	validate.RegisterValidation("lifecycle_stage", validateLifecycleStage)
 
 	return validate
 }
 
-// ReadConfig loads the config from a file, sets all of the defaults/overrides and validates the input.
+// ReadConfig loads the config from a file and its includes, sets all of the defaults/overrides and validates the input.
 func ReadConfig(filePath string, includeAppNames ...string) (Config, error) {
-	content, err := os.ReadFile(filePath)
+	content, _, err := LoadConfigFile(filePath)
 	if err != nil {
 		return Config{}, err
 	}
@@ -111,6 +198,8 @@ func ReadConfig(filePath string, includeAppNames ...string) (Config, error) {
 		return Config{}, err
 	}
 
+	unknownKeyErrs := checkUnknownKeys(content)
+
 	if len(includeAppNames) > 0 {
 		filteredApps := make([]Options, 0, len(c.Applications))
 
@@ -127,13 +216,25 @@ func ReadConfig(filePath string, includeAppNames ...string) (Config, error) {
 
 	NewValidator()
 
-	if err = validate.Struct(&c); err != nil {
+	if err = errors.Join(append(append(unknownKeyErrs, c.interpolateErrs()...), validate.Struct(&c))...); err != nil {
 		return Config{}, err
 	}
 
 	return c, nil
 }
 
+// interpolateErrs returns the errors of the references in the options of the applications.
+func (c Config) interpolateErrs() []error {
+	var errs []error
+	for _, app := range c.Applications {
+		if app.interpolateErr != nil {
+			errs = append(errs, app.interpolateErr)
+		}
+	}
+
+	return errs
+}
+
 // SetDefaults merges the default values into the application configurations and sets any
 // dynamic defaults.
 func SetDefaults(configBytes []byte) (Config, error) {
@@ -144,6 +245,27 @@ func SetDefaults(configBytes []byte) (Config, error) {
 		return Config{}, err
 	}
 
+	// The references are resolved before the defaults are merged, so that the applications inherit the values. The
+	// errors of the applications are kept on the applications, so that only the applications that are run fail.
+	secrets, errs := interpolateConfig(&c)
+
+	appErrs := make(map[int][]error)
+	var configErrs []error
+
+	for _, err := range errs {
+		if i, ok := applicationIndex(err); ok {
+			appErrs[i] = append(appErrs[i], err)
+		} else {
+			configErrs = append(configErrs, err)
+		}
+	}
+
+	if err = errors.Join(configErrs...); err != nil {
+		return Config{}, err
+	}
+
+	c.Secrets = secretsOf(secrets, "config")
+
 	setDynamicDefaults(&c)
 
 	for i := range c.Applications {
@@ -151,6 +273,9 @@ func SetDefaults(configBytes []byte) (Config, error) {
 			return Config{}, err
 		}
 
+		c.Applications[i].Secrets = secretsOf(secrets, "config.default", fmt.Sprintf("config.applications[%d]", i))
+		c.Applications[i].interpolateErr = errors.Join(appErrs[i]...)
+
 		setPostMergeDefaults(&c.Applications[i])
 	}
 
@@ -158,9 +283,10 @@ func SetDefaults(configBytes []byte) (Config, error) {
 }
 
 func setPostMergeDefaults(options *Options) {
-	if options.WaitForResult || options.AutoPromote {
+	if options.WaitForResult || options.AutoPromote || options.OnExistingBuild == ExistingBuildWait {
 		if options.ScanTimeout <= 0 {
 			options.ScanTimeout = 120
+			options.defaultScanTimeout = true
 		}
 
 		if options.ScanPollingInterval < 30 {
@@ -178,11 +304,33 @@ func setDynamicDefaults(config *Config) {
 	if config.Default.Version == "" {
 		config.Default.Version = time.Now().Format("02 Jan 2006 15:04PM Static")
 	}
+
+	if config.CredentialExpiryWarningDays == nil {
+		days := defaultCredentialExpiryWarningDays
+		config.CredentialExpiryWarningDays = &days
+	}
+
+	config.Cache.setDefaults()
 }
 
 func optionsStructLevelValidation(sl validator.StructLevel) {
 	options := sl.Current().Interface().(Options)
 
+	if problem := checkWrapperArgs(options); problem != "" {
+		sl.ReportError(options.WrapperArgs, "WrapperArgs", "WrapperArgs", "wrapper_args", problem)
+	}
+
+	// The teams and business criticality are set either on the application or in its profile block.
+	if options.Profile != nil {
+		if len(options.Teams) > 0 && len(options.Profile.Teams) > 0 {
+			sl.ReportError(options.Teams, "Teams", "Teams", "excluded_with", "profile.teams")
+		}
+
+		if options.Criticality != "" && options.Profile.BusinessCriticality != "" {
+			sl.ReportError(options.Criticality, "Criticality", "Criticality", "excluded_with", "profile.business_criticality")
+		}
+	}
+
 	switch options.Type {
 	case Directory:
 		if !isDir(options.PackageSource) {
@@ -63,6 +63,31 @@ applications:
 				}
 			},
 		},
+		{
+			name: "credential expiry warning days default",
+			args: args{configBytes: []byte(`
+applications:
+  - app_name: Test`)},
+			wantErr: false,
+			validationFunc: func(t *testing.T, tc testConfig, got Config) {
+				if *got.CredentialExpiryWarningDays != defaultCredentialExpiryWarningDays {
+					t.Errorf("SetDefaults() = credential_expiry_warning_days=%d, want credential_expiry_warning_days=%d", *got.CredentialExpiryWarningDays, defaultCredentialExpiryWarningDays)
+				}
+			},
+		},
+		{
+			name: "credential expiry warning days off",
+			args: args{configBytes: []byte(`
+credential_expiry_warning_days: 0
+applications:
+  - app_name: Test`)},
+			wantErr: false,
+			validationFunc: func(t *testing.T, tc testConfig, got Config) {
+				if *got.CredentialExpiryWarningDays != 0 {
+					t.Errorf("SetDefaults() = credential_expiry_warning_days=%d, want credential_expiry_warning_days=0", *got.CredentialExpiryWarningDays)
+				}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
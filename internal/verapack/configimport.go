@@ -0,0 +1,196 @@
+package verapack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/goccy/go-yaml"
+)
+
+var (
+	// applicationsKeyRegex matches the applications key of the config file, with an optional empty flow sequence.
+	applicationsKeyRegex = regexp.MustCompile(`^applications:\s*(\[\s*\])?\s*(#.*)?$`)
+	// sequenceItemRegex matches the first line of an item of a block sequence and captures its indentation.
+	sequenceItemRegex = regexp.MustCompile(`^(\s*)- `)
+	// commentedApplicationRegex matches the first line of an application that is commented out and captures its name.
+	commentedApplicationRegex = regexp.MustCompile(`^\s*#\s*- app_name: (.+?)\s*$`)
+)
+
+// ImportFilter filters the application profiles that are listed by the config import command.
+type ImportFilter struct {
+	Name         string // Pattern of the name, where the * wildcard matches 0 or more characters. The pattern is case-insensitive.
+	Team         string // Name of a team of the application profiles.
+	BusinessUnit string // Name of the business unit of the application profiles.
+}
+
+// ListApplicationProfiles returns the names of the application profiles that match the filter and are not
+// configured yet, sorted by name.
+func ListApplicationProfiles(ctx context.Context, client *veracode.Client, filter ImportFilter, configured []string) ([]string, error) {
+	options := veracode.ListApplicationOptions{Team: filter.Team, BusinessUnit: filter.BusinessUnit, Size: 500}
+
+	var names []string
+
+	for {
+		apps, resp, err := client.Application.ListApplications(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, app := range apps {
+			name := app.Profile.Name
+
+			if filter.Name != "" && !matchAnyModulePattern([]string{strings.ToLower(filter.Name)}, strings.ToLower(name)) {
+				continue
+			}
+
+			if slices.ContainsFunc(configured, func(c string) bool { return strings.EqualFold(c, name) }) {
+				continue
+			}
+
+			names = append(names, name)
+		}
+
+		options.Page++
+		if resp == nil || options.Page >= resp.Page.TotalPages {
+			break
+		}
+	}
+
+	slices.SortFunc(names, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+
+	return names, nil
+}
+
+// configuredApplicationNames returns the app_name of every application in the config file. The config is
+// not validated, because the applications are only used to skip the application profiles that are configured.
+func configuredApplicationNames(content []byte) ([]string, error) {
+	var c struct {
+		Applications []struct {
+			AppName string `yaml:"app_name"`
+		} `yaml:"applications"`
+	}
+
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(c.Applications))
+	for _, app := range c.Applications {
+		names = append(names, app.AppName)
+	}
+
+	return names, nil
+}
+
+// commentedApplicationNames returns the app_name of every application that is commented out in the config file,
+// e.g. the applications that were added by [appendApplications] and have not been completed yet.
+func commentedApplicationNames(content []byte) []string {
+	var names []string
+
+	for _, line := range strings.Split(string(content), "\n") {
+		m := commentedApplicationRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+
+		var name string
+		if err := yaml.Unmarshal([]byte(m[1]), &name); err == nil && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// appendApplications appends a skeleton entry for every application profile to the applications of the
+// config file. The config file is edited as text, so that its comments, formatting and line endings are preserved.
+// The entries are added after the last line of the applications, before any trailing top-level comments. The
+// entries are commented out, because an application without a package_source, artefact_paths or build would fail
+// the validation of the whole config file.
+func appendApplications(content []byte, names []string, credentialsProfile string) ([]byte, error) {
+	eol := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		eol = "\r\n"
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\r\n"), eol)
+
+	key := slices.IndexFunc(lines, func(line string) bool { return applicationsKeyRegex.MatchString(line) })
+
+	indent := "  "
+
+	var insert int
+
+	if key == -1 {
+		lines = append(lines, "", "applications:")
+		insert = len(lines)
+	} else {
+		if m := applicationsKeyRegex.FindStringSubmatch(lines[key]); m[1] != "" {
+			lines[key] = strings.Replace(lines[key], m[1], "", 1)
+		}
+
+		insert = key + 1
+		found := false
+
+		for k := key + 1; k < len(lines); k++ {
+			line := lines[k]
+			trimmed := strings.TrimSpace(line)
+
+			if trimmed == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+				// The next top-level key.
+				break
+			}
+
+			if m := sequenceItemRegex.FindStringSubmatch(line); m != nil && !found {
+				indent = m[1]
+				found = true
+			}
+
+			insert = k + 1
+		}
+	}
+
+	var entries []string
+	for _, name := range names {
+		entries = append(entries, fmt.Sprintf("%s# - app_name: %s", indent, quoteYAMLString(name)))
+		if credentialsProfile != "" {
+			entries = append(entries, fmt.Sprintf("%s#   credentials_profile: %s", indent, quoteYAMLString(credentialsProfile)))
+		}
+		entries = append(entries, fmt.Sprintf("%s#   package_source: C:\\app\\source   # Set [package_source], [artefact_paths] or [build], and uncomment the application to scan it.", indent))
+	}
+
+	lines = slices.Insert(lines, insert, entries...)
+	result := []byte(strings.Join(lines, eol) + eol)
+
+	// Verify that the entries did not break the config file.
+	if _, err := configuredApplicationNames(result); err != nil {
+		return nil, fmt.Errorf("the applications could not be added to the config file: %w", err)
+	}
+
+	added := commentedApplicationNames(result)
+	for _, name := range names {
+		if !slices.Contains(added, name) {
+			return nil, fmt.Errorf("the applications could not be added to the config file: the application '%s' is missing after adding it", name)
+		}
+	}
+
+	return result, nil
+}
+
+// quoteYAMLString returns the value as a double-quoted YAML string if it can not be written as a plain scalar.
+func quoteYAMLString(value string) string {
+	if b, err := yaml.Marshal(value); err == nil && strings.TrimSuffix(string(b), "\n") == value {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
@@ -0,0 +1,69 @@
+package verapack
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAppendApplications(t *testing.T) {
+	content := `default:
+  verbose: false                          # Increase output verbosity.
+
+applications:
+  # Add your applications' config here.
+  - app_name: Example                     # Name of the application profile.
+    package_source: C:\app\source
+
+# auto_refresh_credentials: false         # Automatically re-generate the API credentials before scanning.
+`
+
+	got, err := appendApplications([]byte(content), []string{"Payments API", "Billing: Web"}, "eu")
+	if err != nil {
+		t.Fatalf("appendApplications() error = %v", err)
+	}
+
+	for _, want := range []string{"# Increase output verbosity.", "# Add your applications' config here.", "  # - app_name: Payments API\n  #   credentials_profile: eu\n", `  # - app_name: "Billing: Web"`} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("appendApplications() does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if !strings.HasSuffix(string(got), "\n\n# auto_refresh_credentials: false         # Automatically re-generate the API credentials before scanning.\n") {
+		t.Errorf("appendApplications() did not add the applications before the trailing comments:\n%s", got)
+	}
+
+	// The added applications are commented out, so that they do not fail the validation of the config file.
+	names, _ := configuredApplicationNames(got)
+	if want := []string{"Example"}; !slices.Equal(names, want) {
+		t.Errorf("applications = %v, want %v", names, want)
+	}
+
+	if names, want := commentedApplicationNames(got), []string{"Payments API", "Billing: Web"}; !slices.Equal(names, want) {
+		t.Errorf("commented applications = %v, want %v", names, want)
+	}
+}
+
+func TestAppendApplicationsEmpty(t *testing.T) {
+	got, err := appendApplications([]byte("applications: []\n"), []string{"App"}, "")
+	if err != nil {
+		t.Fatalf("appendApplications() error = %v", err)
+	}
+
+	if names := commentedApplicationNames(got); !slices.Equal(names, []string{"App"}) {
+		t.Errorf("commented applications = %v, want [App]:\n%s", names, got)
+	}
+}
+
+func TestAppendApplicationsLineEndings(t *testing.T) {
+	content := "default:\r\n  verbose: false\r\napplications:\r\n  - app_name: Example\r\n    package_source: C:\\app\\source\r\n"
+
+	got, err := appendApplications([]byte(content), []string{"App"}, "")
+	if err != nil {
+		t.Fatalf("appendApplications() error = %v", err)
+	}
+
+	if n := strings.Count(string(got), "\n"); n != strings.Count(string(got), "\r\n") || n != 7 {
+		t.Errorf("appendApplications() = %q, want 7 lines that end with CRLF", got)
+	}
+}
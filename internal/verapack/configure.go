@@ -0,0 +1,1263 @@
+package verapack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DanCreative/verapack/internal/components/checkbox"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// appHeight is the height in lines of the visual model.
+	appHeight = 20
+	// inputs in the editor are paginated and inputsPerPage
+	// sets the number of inputs per page.
+	inputsPerPage = 3
+
+	inputTypeTextArea = iota
+	inputTypeTextInput
+	inputTypeBool
+)
+
+var helpText = map[string]string{
+	"app_name":                             "Name of the application profile.",
+	"create_profile":                       "Create a application profile if the one provided in AppName does not exist.",
+	"artefact_paths":                       "Path(s) of the folders and files you want to upload to Veracode for scanning.",
+	"version":                              "Name or version of the build that you want to scan. It can be a template that includes {{.Date}}.",
+	"sandbox_name":                         "Name of the sandbox to use when running a sandbox scan or promoting a sandbox scan.",
+	"auto_promote":                         "Wait for the result of sandbox scans, and automatically promote results that pass.",
+	"wait_for_result":                      "Wait for the scan to complete and return the status of the scan.",
+	"scan_timeout":                         "Number of minutes to wait for the scan to complete and pass policy.",
+	"scan_polling_interval":                "Interval, in seconds, to poll for the status of a running scan. Value range is 30 to 120 (two minutes). Default is 30.",
+	"verbose":                              "Displays detailed output.",
+	"auto_cleanup":                         "Automatically remove packaged output after scan completes.",
+	"package_source":                       "Location of the source to package based on the target --type. If the target is directory, \nenter the path to a local directory. If the target is repo, enter the URL to a Git version control system.",
+	"strict":                               "Fail the packaging step on application build failure.",
+	"type":                                 "Specifies the target type you want to package. Values are repo or directory. Default is directory.",
+	"branch":                               "Name of the branch to scan.",
+	"clone_cache":                          "Clone remote repositories from a persistent mirror that is fetched incrementally.",
+	"ref":                                  "Branch, tag, commit SHA or latest-tag to scan. Can not be used together with branch.",
+	"submodules":                           "Initialize the submodules of the repository recursively after cloning it.",
+	"lfs":                                  "Download the git LFS objects of the repository after cloning it.",
+	"sparse_paths":                         "Only check out these directories of the repository.",
+	"source_subdir":                        "Directory inside the source to package.",
+	"git_auth.username":                    "Username to send with the token. Default is x-access-token.",
+	"git_auth.token_env":                   "Name of the environment variable that contains the HTTPS token.",
+	"git_auth.token_file":                  "Path to a file that contains the HTTPS token.",
+	"git_auth.ssh_key":                     "Path to the SSH private key.",
+	"git_auth.known_hosts":                 "Path to a known_hosts file that the host key of the server must be in.",
+	"hooks.pre_package":                    "Command that is run in the source directory, before the auto-packager.",
+	"hooks.post_package":                   "Command that is run in the source directory, after the auto-packager.",
+	"hooks.pre_upload":                     "Command that is run before the artefacts are uploaded.",
+	"hooks.post_scan":                      "Command that is run after the scan has been submitted, or after the result is available.",
+	"hooks.on_failure":                     "Command that is run if any of the tasks of the application failed.",
+	"hooks.timeout":                        "Number of seconds after which a hook is stopped. Default is 600.",
+	"include_modules":                      "Patterns of the names of the top-level modules that are scanned.",
+	"exclude_modules":                      "Patterns of the names of the top-level modules that are not scanned.",
+	"scan_all_non_fatal_top_level_modules": "Scan all of the top-level modules that do not have fatal errors.",
+	"teams":                                "Teams that are assigned to the application profile when it is created by the upload.",
+	"criticality":                          "Business criticality of the application profile when it is created by the upload.",
+	"lifecycle_stage":                      "Lifecycle stage of the build.",
+	"wrapper_args":                         "Additional arguments of the API wrapper's UploadAndScan action, a flag or its value per line.",
+	"on_existing_build":                    "What happens if a previous build is still incomplete. Values are fail, wait or delete. Default is fail.",
+	"skip_if_unchanged":                    "Skip the application if it did not change since its last successful scan.",
+	"include":                              "Glob patterns of the files in the artefacts that are uploaded.",
+	"exclude":                              "Glob patterns of the files in the artefacts that are not uploaded.",
+	"profile.business_criticality":         "Business criticality of the application profile. Values are VeryHigh, High, Medium, Low or VeryLow.",
+	"profile.policy":                       "Name of the policy that is assigned to the application profile.",
+	"profile.teams":                        "Names of the teams that are assigned to the application profile.",
+	"profile.business_unit":                "Name of the business unit of the application profile.",
+	"profile.description":                  "Description of the application profile.",
+	"profile.tags":                         "Tags of the application profile.",
+	"profile.custom_fields":                "Values of the custom fields of the application profile.",
+	"build.command":                        "Shell command that builds the application instead of the auto-packager.",
+	"build.working_dir":                    "Directory in which the command is run, relative to source_subdir or the root of the source.",
+	"build.env":                            "Additional environment variables of the command.",
+	"build.outputs":                        "Glob patterns of the artefacts, relative to the working directory.",
+	"build.timeout":                        "Number of seconds after which the command is stopped. The default value is 3600.",
+	"credentials_profile":                  "Name of the profile in the credentials file to use for the application.",
+	"region":                               "Veracode region of the application's tenant. Values are commercial, european or federal.",
+}
+
+var (
+	// See below diagram of the interface layout:
+	// |---------| ----------------------|
+	// |app list | editor				 |
+	// |         | ----------------------|
+	// |		 | |editorFieldsBlock   ||
+	// |		 | ----------------------|
+	// |		 |  page display         |
+	// |---------| ----------------------|
+
+	// applistStyle is a lipgloss.Style that is used to create the application list block.
+	//
+	// Its height is set to the appHeight.
+	applistStyle = lipgloss.NewStyle().
+			Width(30).
+			Height(appHeight).
+			Padding(0, 1, 1, 1).
+			Border(lipgloss.RoundedBorder())
+
+	// editorStyle is a lipgloss.Style that is used to create the editor block.
+	//
+	// Its height is set to the appHeight.
+	editorStyle = lipgloss.NewStyle().
+			Width(100).
+			Height(appHeight).
+			Padding(0, 1, 1, 1).
+			Border(lipgloss.RoundedBorder(), true, true, true, false)
+
+	// editorFieldsBlock is a lipgloss.Style that contains all of the fields in the editor.
+	// It allows me to easily horizontally center the pages display at the bottom of the
+	// editor block.
+	//
+	// Its width is the same as its parent and its width is the same as its parent minus padding, borders and heading height.
+	editorFieldsBlock = lipgloss.NewStyle().
+				Width(editorStyle.GetWidth()).
+				Height(editorStyle.GetHeight() - editorStyle.GetVerticalPadding() - editorStyle.GetVerticalBorderSize() - 1)
+
+	// headerStyle is a lipgloss.Style that is used for the primary headers.
+	headerStyle = lipgloss.NewStyle().
+			Padding(0, 0, 1, 0).
+			AlignHorizontal(lipgloss.Center).
+			Underline(true)
+
+	// itemStyle is a lipgloss.Style that is used for the items in the application list.
+	itemStyle = lipgloss.NewStyle().
+			Padding(0, 1).
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(darkGray)
+
+	// selectedItemStyle is a lipgloss.Style that is used to show which item is being edited.
+	selectedItemStyle = lipgloss.NewStyle().
+				Padding(0, 1).
+				Border(lipgloss.NormalBorder(), false, false, false, true).
+				BorderForeground(darkBlue).
+				Foreground(darkBlue)
+
+	// selectedFocusedItemStyle is a lipgloss.Style that is used for the items in the application list,
+	// when they are focussed.
+	selectedFocusedItemStyle = lipgloss.NewStyle().
+					Padding(0, 1).
+					Border(lipgloss.NormalBorder(), false, false, false, true).
+					BorderForeground(lightBlue).
+					Foreground(lightBlue)
+
+	// fieldErrorRegex matches the namespace of a validation error of an application, and captures the index
+	// of the application and the path of the field.
+	fieldErrorRegex = regexp.MustCompile(`^Config\.Applications\[(\d+)\]\.(.+)$`)
+	// fieldErrorIndexRegex matches the index of a list or map in the path of the field of a validation error.
+	fieldErrorIndexRegex = regexp.MustCompile(`\[[^\]]*\]`)
+	// commentAppRegex matches the path of a comment of an application in the config file.
+	commentAppRegex = regexp.MustCompile(`^\$\.applications\[(\d+)\]`)
+)
+
+// configureKeyMap contains the key bindings of the config editor.
+type configureKeyMap struct {
+	Up        key.Binding
+	Down      key.Binding
+	Edit      key.Binding
+	Back      key.Binding
+	Next      key.Binding
+	Prev      key.Binding
+	New       key.Binding
+	Duplicate key.Binding
+	Remove    key.Binding
+	Save      key.Binding
+	Quit      key.Binding
+	state     int
+}
+
+func (k configureKeyMap) ShortHelp() []key.Binding {
+	if k.state == 0 {
+		return []key.Binding{k.Quit, k.Up, k.Down, k.Edit, k.New, k.Duplicate, k.Remove, k.Save}
+	}
+
+	return []key.Binding{k.Quit, k.Prev, k.Next, k.Back, k.Save}
+}
+
+func (k configureKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+var configureKeys = configureKeyMap{
+	Up:        key.NewBinding(key.WithKeys("up", "shift+tab"), key.WithHelp("↑", "up")),
+	Down:      key.NewBinding(key.WithKeys("down", "tab"), key.WithHelp("↓", "down")),
+	Edit:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "edit")),
+	Back:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+	Next:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next field")),
+	Prev:      key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous field")),
+	New:       key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "new")),
+	Duplicate: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "duplicate")),
+	Remove:    key.NewBinding(key.WithKeys("ctrl+x", "delete"), key.WithHelp("ctrl+x", "remove")),
+	Save:      key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save")),
+	Quit:      key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+}
+
+// appOrigin is where an application in the editor came from in the config file.
+type appOrigin struct {
+	index int           // Index of the application in the config file, or -1 if it was added in the editor.
+	doc   yaml.MapSlice // Keys of the application in the config file, to keep their order when saving.
+}
+
+// configureTask is the tea model of the config editor. It edits the default and application settings as
+// they are written in the config file, therefore the default values are not merged into the applications.
+// The applications are validated after every change, with the same rules as [ReadConfig].
+type configureTask struct {
+	configPath string
+	doc        yaml.MapSlice   // The config file, to keep the order of the keys and the settings that are not edited.
+	comments   yaml.CommentMap // The comments of the config file, by the path of the value they belong to.
+	saved      []byte          // The config file as it was last saved, without comments, to detect changes.
+
+	config  Config
+	origins []appOrigin // The origins of config.Applications.
+	state   int         // state can be one of the following: 0: application list, 1: editor
+
+	// app list
+
+	selectedApp int // 0 is the default settings, and the applications start at 1.
+
+	// editor
+
+	inputs       []input
+	focusedInput int
+	inputErrs    map[int]error // Errors of input values that can not be converted, by the index of the input.
+
+	errs        validator.ValidationErrors
+	configErr   error // Error of the config file that is not a validation error.
+	dirty       bool
+	confirmQuit bool
+	status      string
+	help        help.Model
+}
+
+func NewConfigureTask(configPath string) (configureTask, error) {
+	c := configureTask{configPath: configPath, inputErrs: make(map[int]error), help: defaultHelp}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return c, err
+	}
+
+	c.comments = yaml.CommentMap{}
+
+	if err = yaml.UnmarshalWithOptions(content, &c.doc, yaml.UseOrderedMap(), yaml.CommentToMap(c.comments)); err != nil {
+		return c, err
+	}
+
+	if err = yaml.Unmarshal(content, &c.config); err != nil {
+		return c, err
+	}
+
+	apps, _ := mapSliceValue(c.doc, "applications").([]any)
+
+	for k := range c.config.Applications {
+		origin := appOrigin{index: k}
+		if k < len(apps) {
+			origin.doc, _ = apps[k].(yaml.MapSlice)
+		}
+
+		c.origins = append(c.origins, origin)
+	}
+
+	NewValidator()
+
+	c.createInputs(reflect.TypeOf(c.config.Default), "", "", nil)
+	c.setInputs()
+	c.validate()
+	c.saved, _ = c.marshal(false)
+	c.dirty = false
+
+	return c, nil
+}
+
+func (m configureTask) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, textinput.Blink)
+}
+
+func (m configureTask) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, configureKeys.Quit):
+			if m.dirty && !m.confirmQuit {
+				m.confirmQuit = true
+				m.status = "There are unsaved changes. Press ctrl+c again to quit without saving."
+				return m, nil
+			}
+
+			return m, tea.Quit
+
+		case key.Matches(msg, configureKeys.Save):
+			m.save()
+			return m, nil
+		}
+
+		m.confirmQuit = false
+	}
+
+	if m.state == 0 {
+		// state = application list
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case msg.String() == "q":
+				if !m.dirty {
+					return m, tea.Quit
+				}
+			case key.Matches(msg, configureKeys.New):
+				cmds = append(cmds, m.newApplication())
+			case key.Matches(msg, configureKeys.Duplicate):
+				cmds = append(cmds, m.duplicateApplication())
+			case key.Matches(msg, configureKeys.Remove):
+				m.removeApplication()
+			case key.Matches(msg, configureKeys.Up):
+				m.prevApplication()
+			case key.Matches(msg, configureKeys.Down):
+				m.nextApplication()
+			case key.Matches(msg, configureKeys.Edit):
+				cmds = append(cmds, m.setState(1))
+			}
+		}
+
+		return m, tea.Batch(cmds...)
+	}
+
+	// state = editor
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, configureKeys.Next):
+			return m, m.nextInput()
+		case key.Matches(msg, configureKeys.Prev):
+			return m, m.prevInput()
+		case key.Matches(msg, configureKeys.Back):
+			return m, m.setState(0)
+		}
+	}
+
+	cmds = append(cmds, m.inputs[m.focusedInput].Update(msg))
+
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.setOptions()
+		m.validate()
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m configureTask) View() string {
+	// applist and editor string variables are used to build out the respective views and
+	// are then joined at the end.
+	var applist, editor string
+
+	// application list
+	names := make([]string, 0, len(m.config.Applications)+1)
+	names = append(names, "default")
+	for _, app := range m.config.Applications {
+		if app.AppName == "" {
+			// app.AppName will only be empty if a new application is being created.
+			// In which case, indicate that to the user.
+			app.AppName = "+"
+		}
+		names = append(names, app.AppName)
+	}
+
+	// Only show the items around the selected item if they do not fit.
+	visible := appHeight - 3
+	start := 0
+	if len(names) > visible {
+		start = min(max(m.selectedApp-visible/2, 0), len(names)-visible)
+	}
+
+	for k := start; k < min(start+visible, len(names)); k++ {
+		name := names[k]
+
+		if k == m.selectedApp {
+			// Show whether an item in the application list is:
+			if m.state == 0 {
+				// Selected
+				name = selectedFocusedItemStyle.Render(name)
+			} else {
+				// Being Edited
+				name = selectedItemStyle.Render(name)
+			}
+		} else {
+			// Non of the above
+			name = itemStyle.Render(name)
+		}
+
+		if k > 0 && len(m.appErrors(k)) > 0 {
+			name += redForeground.Render(" ✗")
+		}
+
+		applist += name + "\n"
+	}
+
+	// editor
+
+	// display is the rendered visual of the paginator.
+	var display string
+
+	// pageStart is the index of the start of the page that m.focusedInput is on and
+	// pageEnd is the end of that page.
+	var pageStart, pageEnd int
+
+	// Paginator sets display, pageStart and pageEnd
+	display, pageStart, pageEnd = Pagination(len(m.inputs), inputsPerPage, m.focusedInput)
+
+	invalid := m.invalidFields()
+
+	for i := pageStart; i < pageEnd+1; i++ {
+		editor += m.inputs[i].View(m.state, i == m.focusedInput, invalid[m.inputs[i].fieldName] || m.inputErrs[i] != nil)
+	}
+
+	editor = lipgloss.JoinVertical(lipgloss.Center, editorFieldsBlock.Render(editor), display)
+
+	// Change the border colour of the app list/editor depending on which the user is focusing on.
+	if m.state == 0 {
+		applist = applistStyle.BorderForeground(lightBlue).Render(headerStyle.Render("Applications") + "\n" + applist)
+		editor = editorStyle.BorderForeground(darkGray).Render(headerStyle.Render("Details") + "\n" + editor)
+	} else {
+		applist = applistStyle.BorderForeground(darkGray).Render(headerStyle.Render("Applications") + "\n" + applist)
+		editor = editorStyle.BorderForeground(lightBlue).Render(headerStyle.Render("Details") + "\n" + editor)
+	}
+
+	s := lipgloss.JoinHorizontal(lipgloss.Top, applist, editor) + "\n"
+
+	// Errors of the focused input, followed by the validation errors of the selected application.
+	var errs []error
+	if err := m.inputErrs[m.focusedInput]; err != nil && m.state == 1 {
+		errs = append(errs, err)
+	}
+
+	if m.configErr != nil {
+		errs = append(errs, m.configErr)
+	}
+
+	if appErrs := m.appErrors(m.selectedApp); len(appErrs) > 0 {
+		errs = append(errs, appErrs)
+	}
+
+	if len(errs) > 0 {
+		s += rawRenderErrors(editorStyle.GetWidth()+applistStyle.GetWidth(), errs...) + "\n"
+	}
+
+	status := m.status
+	if status == "" && m.dirty {
+		status = "Unsaved changes."
+	}
+
+	if status != "" {
+		s += darkGrayForeground.Render(status) + "\n"
+	}
+
+	keys := configureKeys
+	keys.state = m.state
+
+	return s + m.help.View(keys)
+}
+
+func (m configureTask) GetHelp() help.KeyMap {
+	keys := configureKeys
+	keys.state = m.state
+	return keys
+}
+
+// selectedOptions returns the settings that are being edited.
+func (m *configureTask) selectedOptions() *Options {
+	if m.selectedApp == 0 {
+		return &m.config.Default
+	}
+
+	return &m.config.Applications[m.selectedApp-1]
+}
+
+// nextApplication changes the selected app to the next one in the list.
+// It also wraps around if the end of the list is reached.
+func (m *configureTask) nextApplication() {
+	m.selectedApp = (m.selectedApp + 1) % (len(m.config.Applications) + 1)
+	m.setInputs()
+}
+
+// prevApplication changes the selected app to the prev one in the list.
+// It also wraps around if the start of the list is reached.
+func (m *configureTask) prevApplication() {
+	m.selectedApp--
+	// Wrap around
+	if m.selectedApp < 0 {
+		m.selectedApp = len(m.config.Applications)
+	}
+
+	m.setInputs()
+}
+
+// newApplication adds a new application to m.config.Applications and
+// automatically enters the editor for the new application.
+func (m *configureTask) newApplication() tea.Cmd {
+	return m.addApplication(Options{}, appOrigin{index: -1})
+}
+
+// duplicateApplication adds a copy of the selected application to m.config.Applications and
+// automatically enters the editor for the copy.
+func (m *configureTask) duplicateApplication() tea.Cmd {
+	if m.selectedApp == 0 {
+		m.status = "The default settings can not be duplicated."
+		return nil
+	}
+
+	// The copy is made through YAML, so that it does not share any pointers, slices or maps.
+	origin := m.origins[m.selectedApp-1]
+
+	b, err := yaml.Marshal(structToMapSlice(reflect.ValueOf(*m.selectedOptions()), origin.doc))
+	if err != nil {
+		m.status = err.Error()
+		return nil
+	}
+
+	var app Options
+	if err = yaml.Unmarshal(b, &app); err != nil {
+		m.status = err.Error()
+		return nil
+	}
+
+	app.AppName += " (copy)"
+
+	return m.addApplication(app, appOrigin{index: -1, doc: origin.doc})
+}
+
+func (m *configureTask) addApplication(app Options, origin appOrigin) tea.Cmd {
+	m.config.Applications = append(m.config.Applications, app)
+	m.origins = append(m.origins, origin)
+	m.selectedApp = len(m.config.Applications)
+	m.focusedInput = 0
+	m.status = ""
+
+	m.setInputs()
+	m.validate()
+	return m.setState(1)
+}
+
+// removeApplication removes the selected application from m.config.Applications.
+func (m *configureTask) removeApplication() {
+	if m.selectedApp == 0 {
+		m.status = "The default settings can not be removed."
+		return
+	}
+
+	name := m.selectedOptions().AppName
+
+	m.config.Applications = slices.Delete(m.config.Applications, m.selectedApp-1, m.selectedApp)
+	m.origins = slices.Delete(m.origins, m.selectedApp-1, m.selectedApp)
+	m.selectedApp = min(m.selectedApp, len(m.config.Applications))
+	m.status = fmt.Sprintf("Removed '%s'.", name)
+
+	m.setInputs()
+	m.validate()
+}
+
+// setState changes the state of the model.
+//
+// Available states:
+//
+//   - 0: application list
+//   - 1: editor
+//
+// If another state is provided, the method will panic.
+// The panic is for development only. It will always be caught during testing.
+func (m *configureTask) setState(newState int) tea.Cmd {
+	switch newState {
+	case 0:
+		// changing to applist
+		m.setOptions()
+		m.state = newState
+		m.inputs[m.focusedInput].Blur()
+		m.setInputs()
+		m.validate()
+		return nil
+	case 1:
+		// changing to editor
+		m.state = newState
+		return m.inputs[m.focusedInput].Focus()
+	default:
+		panic(fmt.Sprintf("state: %d does not exist", newState))
+	}
+}
+
+// createInputs reflects the Options struct to create a list of inputs.
+// This was done so that the Options struct can be updated in the future
+// without having to manually update the UI as well.
+//
+// The fields of nested structs, e.g. hooks, get their own inputs, which are named with the
+// path of the field, e.g. hooks.pre_package.
+//
+// createInputs currently only supports: string, int, bool, *bool, []string, map[string]string and
+// (pointers to) structs of those. If the field is non of the above, then the method will panic.
+// The panic is for development only. It will always be caught during testing.
+//
+// createInputs uses the yaml tag as the name of the field. If the yaml tag is
+// set to "-" it is ignored.
+//
+// createInputs is only run once when the configureTask is initiated.
+func (m *configureTask) createInputs(t reflect.Type, namePrefix, fieldPrefix string, index []int) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		tag, ok := f.Tag.Lookup("yaml")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		tag = namePrefix + strings.Split(tag, ",")[0]
+		fieldName := fieldPrefix + f.Name
+		fieldIndex := append(slices.Clone(index), i)
+
+		// the inputs for any fields that are required, will be set to required.
+		validateTag, _ := f.Tag.Lookup("validate")
+		rules, _, _ := strings.Cut(validateTag, ",dive")
+		isRequired := slices.Contains(strings.Split(rules, ","), "required")
+
+		kind := f.Type.Kind()
+		if kind == reflect.Ptr {
+			kind = f.Type.Elem().Kind()
+		}
+
+		switch kind {
+		case reflect.String, reflect.Int:
+			m.inputs = append(m.inputs, newInput(tag, fieldName, fieldIndex, isRequired, inputTypeTextInput))
+		case reflect.Bool:
+			m.inputs = append(m.inputs, newInput(tag, fieldName, fieldIndex, isRequired, inputTypeBool))
+		case reflect.Slice, reflect.Map:
+			m.inputs = append(m.inputs, newInput(tag, fieldName, fieldIndex, isRequired, inputTypeTextArea))
+		case reflect.Struct:
+			m.createInputs(f.Type, tag+".", fieldName+".", fieldIndex)
+		default:
+			panic(fmt.Sprintf("kind: %s is not currently supported", f.Type.Kind()))
+		}
+	}
+}
+
+// setInputs updates the inputs with the field values of the selected Options struct.
+func (m *configureTask) setInputs() {
+	v := reflect.ValueOf(m.selectedOptions()).Elem()
+	clear(m.inputErrs)
+
+	for i := range m.inputs {
+		f, ok := fieldByIndex(v, m.inputs[i].index, false)
+		if !ok {
+			m.inputs[i].Reset()
+			continue
+		}
+
+		switch f.Kind() {
+		case reflect.Ptr:
+			if f.IsNil() {
+				m.inputs[i].SetValue("false")
+			} else {
+				m.inputs[i].SetValue(strconv.FormatBool(f.Elem().Bool()))
+			}
+		case reflect.Bool:
+			m.inputs[i].SetValue(strconv.FormatBool(f.Bool()))
+		case reflect.Int:
+			if f.Int() == 0 {
+				m.inputs[i].Reset()
+			} else {
+				m.inputs[i].SetValue(strconv.FormatInt(f.Int(), 10))
+			}
+		case reflect.Slice:
+			slice, ok := f.Interface().([]string)
+			if !ok {
+				panic("slice currently only supports []string")
+			}
+
+			if len(slice) == 0 {
+				m.inputs[i].Reset()
+			} else {
+				m.inputs[i].SetValue(strings.Join(slice, "\n"))
+			}
+
+		case reflect.Map:
+			keys := f.MapKeys()
+			lines := make([]string, 0, len(keys))
+			for _, k := range keys {
+				lines = append(lines, k.String()+"="+f.MapIndex(k).String())
+			}
+
+			slices.Sort(lines)
+
+			if len(lines) == 0 {
+				m.inputs[i].Reset()
+			} else {
+				m.inputs[i].SetValue(strings.Join(lines, "\n"))
+			}
+
+		case reflect.String:
+			m.inputs[i].SetValue(f.String())
+		}
+
+		// Set the cursor to the end of input.
+		// This is for when switching from an
+		// input with a shorter value to an input
+		// with a longer value to prevent the cursor
+		// from being placed in the middle of the longer
+		// value.
+		m.inputs[i].CursorEnd()
+	}
+}
+
+// setOptions updates the field values of the selected Options struct using the input values.
+// Nested structs that are pointers are only created once one of their fields is set, and are
+// removed again once all of their fields are empty.
+func (m *configureTask) setOptions() {
+	v := reflect.ValueOf(m.selectedOptions()).Elem()
+
+	for i := range m.inputs {
+		value := m.inputs[i].Value()
+		empty := value == "" || value == "false"
+
+		f, ok := fieldByIndex(v, m.inputs[i].index, !empty)
+		if !ok {
+			continue
+		}
+
+		delete(m.inputErrs, i)
+
+		switch f.Kind() {
+		case reflect.Ptr:
+			b, _ := strconv.ParseBool(value)
+			if !f.IsNil() || b {
+				f.Set(reflect.ValueOf(&b))
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(value); err == nil {
+				f.SetBool(b)
+			}
+		case reflect.Int:
+			if value == "" {
+				f.SetInt(0)
+			} else if in, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				f.SetInt(int64(in))
+			} else {
+				m.inputErrs[i] = fmt.Errorf("%s: '%s' is not a number", m.inputs[i].name, value)
+			}
+		case reflect.Slice:
+			var lines []string
+			for _, line := range strings.Split(value, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					lines = append(lines, line)
+				}
+			}
+
+			if len(lines) == 0 {
+				f.Set(reflect.Zero(f.Type()))
+			} else {
+				f.Set(reflect.ValueOf(lines))
+			}
+
+		case reflect.Map:
+			values := make(map[string]string)
+
+			for _, line := range strings.Split(value, "\n") {
+				if line = strings.TrimSpace(line); line == "" {
+					continue
+				}
+
+				k, v, ok := strings.Cut(line, "=")
+				if !ok || strings.TrimSpace(k) == "" {
+					m.inputErrs[i] = fmt.Errorf("%s: '%s' must be in the format: KEY=value", m.inputs[i].name, line)
+					continue
+				}
+
+				values[strings.TrimSpace(k)] = v
+			}
+
+			if len(values) == 0 {
+				f.Set(reflect.Zero(f.Type()))
+			} else {
+				f.Set(reflect.ValueOf(values))
+			}
+
+		case reflect.String:
+			f.SetString(value)
+		}
+	}
+
+	// Remove the nested structs of which all of the fields are empty.
+	for i := range v.NumField() {
+		if f := v.Field(i); f.Kind() == reflect.Ptr && !f.IsNil() && f.Elem().Kind() == reflect.Struct && f.Elem().IsZero() {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+}
+
+// fieldByIndex returns the nested field of the struct by its index path. Nil pointers to structs on the path
+// are created if create is true, otherwise the field can not be found.
+func fieldByIndex(v reflect.Value, index []int, create bool) (reflect.Value, bool) {
+	for k, i := range index {
+		if k > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !create {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(i)
+	}
+
+	return v, true
+}
+
+// nextInput switches the focus to the next input in the editor.
+// It also wraps around if the end of the list is reached.
+func (m *configureTask) nextInput() tea.Cmd {
+	if len(m.inputs) == 0 {
+		panic("no inputs")
+	}
+
+	m.inputs[m.focusedInput].Blur()
+	m.focusedInput = (m.focusedInput + 1) % len(m.inputs)
+	return m.inputs[m.focusedInput].Focus()
+}
+
+// prevInput switches the focus to the prev input in the editor.
+// It also wraps around if the start of the list is reached.
+func (m *configureTask) prevInput() tea.Cmd {
+	if len(m.inputs) == 0 {
+		panic("no inputs")
+	}
+	m.inputs[m.focusedInput].Blur()
+
+	m.focusedInput--
+	// Wrap around
+	if m.focusedInput < 0 {
+		m.focusedInput = len(m.inputs) - 1
+	}
+
+	return m.inputs[m.focusedInput].Focus()
+}
+
+// validate validates the config with the same rules as [ReadConfig], by merging the default values
+// into the applications like the config file would be.
+func (m *configureTask) validate() {
+	m.errs, m.configErr = nil, nil
+
+	content, err := m.marshal(false)
+	if err != nil {
+		m.configErr = err
+		return
+	}
+
+	m.dirty = !bytes.Equal(content, m.saved)
+	if m.dirty {
+		m.status = ""
+	}
+
+	c, err := SetDefaults(content)
+	if err != nil {
+		m.configErr = err
+		return
+	}
+
+	if err = validate.Struct(&c); err != nil && !errors.As(err, &m.errs) {
+		m.configErr = err
+	}
+
+	// The settings that the editor does not know are kept, but they are still reported.
+	m.configErr = errors.Join(append(append(checkUnknownKeys(content), c.interpolateErrs()...), m.configErr)...)
+}
+
+// appErrors returns the validation errors of the item in the application list. The default settings
+// have the validation errors of all of the applications, because they are merged into them.
+func (m configureTask) appErrors(item int) validator.ValidationErrors {
+	if item == 0 {
+		return m.errs
+	}
+
+	var r validator.ValidationErrors
+
+	for _, e := range m.errs {
+		if app, _ := fieldErrorLocation(e); app == item-1 {
+			r = append(r, e)
+		}
+	}
+
+	return r
+}
+
+// invalidFields returns the names of the fields of the selected item in the application list that
+// have validation errors.
+func (m configureTask) invalidFields() map[string]bool {
+	r := make(map[string]bool)
+
+	for _, e := range m.appErrors(m.selectedApp) {
+		if _, field := fieldErrorLocation(e); field != "" {
+			r[field] = true
+		}
+	}
+
+	return r
+}
+
+// fieldErrorLocation returns the index of the application and the path of the field of the validation
+// error, e.g. Hooks.Timeout. The index is -1 if the error does not belong to an application.
+func fieldErrorLocation(e validator.FieldError) (int, string) {
+	match := fieldErrorRegex.FindStringSubmatch(e.StructNamespace())
+	if match == nil {
+		return -1, ""
+	}
+
+	app, _ := strconv.Atoi(match[1])
+
+	return app, fieldErrorIndexRegex.ReplaceAllString(match[2], "")
+}
+
+// save writes the config to the config file. The comments of the config file are kept with the values they
+// belong to, and the comments of removed applications are removed.
+func (m *configureTask) save() {
+	content, err := m.marshal(true)
+	if err == nil {
+		err = os.WriteFile(m.configPath, content, 0600)
+	}
+
+	if err != nil {
+		m.status = "The config file could not be saved: " + err.Error()
+		return
+	}
+
+	m.saved, _ = m.marshal(false)
+	m.dirty = false
+	m.status = "Saved to " + m.configPath
+
+	if len(m.errs) > 0 || m.configErr != nil {
+		m.status += ". The config still has validation errors."
+	}
+}
+
+// marshal returns the config file with the edited settings, and with the comments if withComments is true.
+func (m configureTask) marshal(withComments bool) ([]byte, error) {
+	doc := slices.Clone(m.doc)
+
+	apps := make([]any, len(m.config.Applications))
+	for k, app := range m.config.Applications {
+		apps[k] = structToMapSlice(reflect.ValueOf(app), m.origins[k].doc)
+	}
+
+	def, _ := mapSliceValue(doc, "default").(yaml.MapSlice)
+	def = structToMapSlice(reflect.ValueOf(m.config.Default), def)
+
+	if k := slices.IndexFunc(doc, func(item yaml.MapItem) bool { return item.Key == "default" }); k != -1 {
+		doc[k].Value = def
+	} else if len(def) > 0 {
+		doc = slices.Insert(doc, 0, yaml.MapItem{Key: "default", Value: def})
+	}
+
+	if k := slices.IndexFunc(doc, func(item yaml.MapItem) bool { return item.Key == "applications" }); k != -1 {
+		doc[k].Value = apps
+	} else {
+		doc = append(doc, yaml.MapItem{Key: "applications", Value: apps})
+	}
+
+	opts := []yaml.EncodeOption{yaml.IndentSequence(true), yaml.UseSingleQuote(true)}
+	if withComments {
+		opts = append(opts, yaml.WithComment(m.applicationComments()))
+	}
+
+	return yaml.MarshalWithOptions(doc, opts...)
+}
+
+// applicationComments returns the comments of the config file, where the paths of the comments of the
+// applications are changed to their index in the editor. The comments before the first application stay
+// before the first application.
+func (m configureTask) applicationComments() yaml.CommentMap {
+	newIndex := make(map[int]int)
+	for k, origin := range m.origins {
+		if origin.index != -1 {
+			newIndex[origin.index] = k
+		}
+	}
+
+	r := make(yaml.CommentMap)
+
+	for path, comments := range m.comments {
+		if match := commentAppRegex.FindStringSubmatch(path); match != nil {
+			index, _ := strconv.Atoi(match[1])
+
+			k, ok := newIndex[index]
+			if path == "$.applications[0]" && len(m.origins) > 0 {
+				k, ok = 0, true
+			}
+
+			if !ok {
+				continue
+			}
+
+			path = fmt.Sprintf("$.applications[%d]", k) + strings.TrimPrefix(path, match[0])
+		}
+
+		r[path] = append(r[path], comments...)
+	}
+
+	return r
+}
+
+// mapSliceValue returns the value of the key in the map slice, or nil if it does not exist.
+func mapSliceValue(doc yaml.MapSlice, key string) any {
+	for _, item := range doc {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+
+	return nil
+}
+
+// structToMapSlice returns the fields of the struct that are set, named by their yaml tag. The keys are in
+// the order of doc, followed by the other fields in the order of the struct. The keys of doc that are not
+// fields of the struct are kept, so that settings that the editor does not know are not lost.
+func structToMapSlice(v reflect.Value, doc yaml.MapSlice) yaml.MapSlice {
+	t := v.Type()
+
+	names := make([]string, 0, t.NumField())
+	values := make(map[string]any, t.NumField())
+
+	for i := range t.NumField() {
+		tag, ok := t.Field(i).Tag.Lookup("yaml")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		names = append(names, name)
+
+		nested, _ := mapSliceValue(doc, name).(yaml.MapSlice)
+		if value, ok := fieldToYAML(v.Field(i), nested); ok {
+			values[name] = value
+		}
+	}
+
+	var r yaml.MapSlice
+
+	for _, item := range doc {
+		name, _ := item.Key.(string)
+
+		switch {
+		case !slices.Contains(names, name):
+			r = append(r, item)
+		case values[name] != nil:
+			r = append(r, yaml.MapItem{Key: name, Value: values[name]})
+			delete(values, name)
+		}
+	}
+
+	for _, name := range names {
+		if values[name] != nil {
+			r = append(r, yaml.MapItem{Key: name, Value: values[name]})
+		}
+	}
+
+	return r
+}
+
+// fieldToYAML returns the YAML value of the field, and whether it is set. Empty values are not set,
+// because they are the same as omitting the field.
+func fieldToYAML(f reflect.Value, doc yaml.MapSlice) (any, bool) {
+	switch f.Kind() {
+	case reflect.Ptr:
+		if f.IsNil() {
+			return nil, false
+		}
+
+		if f.Elem().Kind() == reflect.Struct {
+			return fieldToYAML(f.Elem(), doc)
+		}
+
+		return f.Elem().Interface(), true
+
+	case reflect.Struct:
+		ms := structToMapSlice(f, doc)
+		return ms, len(ms) > 0
+
+	case reflect.Map:
+		keys := make([]string, 0, f.Len())
+		for _, k := range f.MapKeys() {
+			keys = append(keys, k.String())
+		}
+
+		slices.Sort(keys)
+
+		ms := make(yaml.MapSlice, 0, len(keys))
+		for _, k := range keys {
+			ms = append(ms, yaml.MapItem{Key: k, Value: f.MapIndex(reflect.ValueOf(k)).String()})
+		}
+
+		return ms, len(ms) > 0
+
+	case reflect.String:
+		return f.String(), f.Len() > 0
+
+	default:
+		return f.Interface(), !f.IsZero()
+	}
+}
+
+// input wraps the inputer interface and provides meta data for visualization and updating struct fields.
+// It also provides methods for handling unique input cases.
+type input struct {
+	name       string // display name/yaml tag
+	fieldName  string // Struct field name
+	index      []int  // Index path of the struct field, see [reflect.Value.FieldByIndex].
+	isRequired bool
+	inputer
+}
+
+// Update wraps and handles the unique Update methods for all of the supported input types.
+func (i *input) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m := i.inputer.(type) {
+	case *checkbox.Model:
+		var b checkbox.Model
+		b, cmd = m.Update(msg)
+		i.inputer = &b
+	case *textarea.Model:
+		var in textarea.Model
+		in, cmd = m.Update(msg)
+		i.inputer = &in
+	case *textinput.Model:
+		var in textinput.Model
+		in, cmd = m.Update(msg)
+		i.inputer = &in
+	}
+
+	return cmd
+}
+
+func (i *input) View(state int, focused, invalid bool) string {
+	var req string
+	if i.isRequired {
+		req = redForeground.Render("*")
+	}
+
+	if invalid {
+		req += redForeground.Render(" ✗")
+	}
+
+	text := helpText[i.name]
+	if _, ok := i.inputer.(*textarea.Model); ok {
+		text += " One value per line."
+	}
+
+	if focused && state == 1 {
+		// Being focused
+		return fmt.Sprintf("%s%s\n%s\n%s\n",
+			lightBlueForeground.Render(i.name),
+			req,
+			darkGrayForeground.Render(text),
+			i.inputer.View(),
+		)
+	} else if focused && state == 0 {
+		// The last input that the user was editing shown while they are focused on the app list.
+		return fmt.Sprintf("%s%s\n%s\n%s\n",
+			darkBlueForeground.Render(i.name),
+			req,
+			darkGrayForeground.Render(text),
+			i.inputer.View(),
+		)
+	} else {
+		// Not being focused
+		return fmt.Sprintf("%s%s\n%s\n", i.name, req, i.inputer.View())
+	}
+}
+
+// inputer provides a standard interface for all inputs.
+type inputer interface {
+	Blur()
+	Focus() tea.Cmd
+	Reset()
+	SetValue(s string)
+	Value() string
+	View() string
+	CursorEnd()
+}
+
+// newInput creates and returns a new input. This function is a input factory that sets the
+// embedded inputer field to a concrete input struct based on provided t int type.
+//
+// All input customization is handled here.
+//
+// if argument t does not match available options, then the function will panic.
+// The panic is for development only. It will always be caught during testing.
+func newInput(name, fieldName string, index []int, isRequired bool, t int) input {
+	i := input{
+		name:       name,
+		fieldName:  fieldName,
+		index:      index,
+		isRequired: isRequired,
+	}
+
+	switch t {
+	case inputTypeTextArea:
+		ta := textarea.New()
+		ta.SetHeight(3)
+		ta.SetWidth(editorStyle.GetWidth() - editorStyle.GetHorizontalFrameSize())
+		i.inputer = &ta
+
+	case inputTypeTextInput:
+		ti := textinput.New()
+		i.inputer = &ti
+
+	case inputTypeBool:
+		bi := checkbox.New()
+		i.inputer = &bi
+
+	default:
+		panic(fmt.Sprintf("type: %d is not supported", t))
+	}
+
+	return i
+}
+
+// Pagination is a function with named return values, that renders the paginator and
+// calculates the pageStartIndex and pageEndIndex using provided: totalElements, inputsPerPage and
+// currentElementIndex, before returning them.
+func Pagination(totalElements, inputsPerPage, currentElementIndex int) (display string, pageStartIndex int, pageEndIndex int) {
+	numPages := int(math.Ceil(float64(totalElements) / float64(inputsPerPage)))
+
+	pageStartIndex = (currentElementIndex / inputsPerPage) * inputsPerPage
+	pageEndIndex = int(math.Min(float64(pageStartIndex+inputsPerPage-1), float64(totalElements-1)))
+
+	currentPage := int(math.Ceil(float64(pageStartIndex) / float64(inputsPerPage)))
+
+	for page := range numPages {
+		if currentPage == page {
+			display += lipgloss.NewStyle().Width(3).Background(lightBlue).AlignHorizontal(lipgloss.Center).Render(strconv.Itoa(page + 1))
+		} else {
+			display += lipgloss.NewStyle().Width(3).Foreground(darkGray).AlignHorizontal(lipgloss.Center).Render(strconv.Itoa(page + 1))
+		}
+	}
+
+	return
+}
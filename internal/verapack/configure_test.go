@@ -0,0 +1,84 @@
+package verapack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigureTaskSave(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	content := `default:
+  verbose: true # Increase output verbosity.
+applications:
+  # Add your applications' config here.
+  - app_name: Removed # Comment of the removed application.
+    package_source: C:\removed
+  - package_source: C:\app\source
+    app_name: Example # Name of the application profile.
+    unknown_key: kept
+auto_refresh_credentials: false
+`
+
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewConfigureTask(configPath)
+	if err != nil {
+		t.Fatalf("NewConfigureTask() error = %v", err)
+	}
+
+	if m.dirty {
+		t.Error("NewConfigureTask() is dirty before any changes")
+	}
+
+	m.selectedApp = 1
+	m.removeApplication()
+
+	m.selectedApp = 1
+	m.setInputs()
+	for i := range m.inputs {
+		if m.inputs[i].name == "hooks.timeout" {
+			m.inputs[i].SetValue("30")
+		}
+	}
+	m.setOptions()
+	m.validate()
+
+	if !m.dirty {
+		t.Error("configureTask is not dirty after changes")
+	}
+
+	m.save()
+
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(b)
+
+	for _, want := range []string{
+		"verbose: true # Increase output verbosity.",
+		"# Add your applications' config here.\n  - package_source: 'C:\\app\\source'",
+		"app_name: Example # Name of the application profile.",
+		"unknown_key: kept",
+		"hooks:\n      timeout: 30",
+		"auto_refresh_credentials: false",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saved config does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "Removed") {
+		t.Errorf("saved config contains the removed application:\n%s", got)
+	}
+
+	if m.dirty {
+		t.Error("configureTask is dirty after saving")
+	}
+}
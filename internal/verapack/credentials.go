@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/DanCreative/veracode-go/veracode"
 	"github.com/charmbracelet/bubbles/help"
@@ -12,32 +13,51 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/ini.v1"
 )
 
 const (
-	credentialFileLegacyFormat = "[default]\nveracode_api_key_id     = %s\nveracode_api_key_secret = %s"
-	credentialFileFormat       = "api:\n  key-id: %s\n  key-secret: %s"
+	credentialFileFormat = "api:\n  key-id: %s\n  key-secret: %s"
+
+	// defaultCredentialExpiryWarningDays is the default number of days before the API credentials
+	// expire, in which the user will be warned.
+	defaultCredentialExpiryWarningDays = 30
 )
 
-// setLegacyCredentialsFile creates/truncates the credential file: %home%/.veracode/credential
-func setLegacyCredentialsFile(homeDir, apiKey, apiSecret string) error {
-	file, err := os.Create(filepath.Join(homeDir, ".veracode", "credentials"))
+// setLegacyCredentialsFile sets the credentials of the provided profile in the credential file: %home%/.veracode/credentials
+//
+// The other profiles in the file are preserved. If profile is empty, the default profile is set.
+func setLegacyCredentialsFile(homeDir, profile, apiKey, apiSecret string) error {
+	if profile == "" {
+		profile = defaultCredentialsProfile
+	}
+
+	filePath := filepath.Join(homeDir, ".veracode", "credentials")
+
+	file, err := ini.LooseLoad(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	_, err = file.WriteString(fmt.Sprintf(credentialFileLegacyFormat, apiKey, apiSecret))
+	section := file.Section(profile)
+	section.Key("veracode_api_key_id").SetValue(apiKey)
+	section.Key("veracode_api_key_secret").SetValue(apiSecret)
+
+	f, err := createPrivateFile(filePath)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
-	return nil
+	_, err = file.WriteTo(f)
+	return err
 }
 
 // setCredentialsFile creates/truncates the credential file: %home%/.veracode/veracode.yml
+//
+// The file only supports a single set of credentials, therefore it is only set for the default profile.
 func setCredentialsFile(homeDir, apiKey, apiSecret string) error {
-	file, err := os.Create(filepath.Join(homeDir, ".veracode", "veracode.yml"))
+	file, err := createPrivateFile(filepath.Join(homeDir, ".veracode", "veracode.yml"))
 	if err != nil {
 		return err
 	}
@@ -51,6 +71,95 @@ func setCredentialsFile(homeDir, apiKey, apiSecret string) error {
 	return nil
 }
 
+// createPrivateFile creates/truncates a file that, if it is created, is only accessible by the current user.
+func createPrivateFile(filePath string) (*os.File, error) {
+	return os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+}
+
+// setCredentialsFiles sets the credentials of the provided profile in both of the credential files.
+func setCredentialsFiles(homeDir, profile, apiKey, apiSecret string) error {
+	if profile == "" || profile == defaultCredentialsProfile {
+		if err := setCredentialsFile(homeDir, apiKey, apiSecret); err != nil {
+			return err
+		}
+	}
+
+	return setLegacyCredentialsFile(homeDir, profile, apiKey, apiSecret)
+}
+
+// credentialsExpireWithin returns whether the credentials expire within the provided number of days.
+func credentialsExpireWithin(expiry time.Time, days int) bool {
+	return time.Until(expiry) < time.Duration(days)*24*time.Hour
+}
+
+// CheckCredentialExpiry checks when the API credentials expire, before any uploads start.
+//
+// If they expire within the configured warning window, it either returns a warning for the user, or if
+// [Config].AutoRefreshCredentials is set, it re-generates the credentials, updates both of the credential
+// files and the client, and returns a message containing the new expiry date.
+//
+// An empty message is returned if the credentials are not about to expire.
+func CheckCredentialExpiry(ctx context.Context, client *ProfileClient, c Config, homeDir string) (string, error) {
+	creds, _, err := client.Identity.SelfGetCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	expiry := creds.ExpirationTs.Local()
+
+	if !credentialsExpireWithin(expiry, *c.CredentialExpiryWarningDays) {
+		return "", nil
+	}
+
+	if !c.AutoRefreshCredentials {
+		refreshCmd := "verapack credentials refresh"
+		if client.Profile != "" {
+			refreshCmd += " --profile " + client.Profile
+		}
+
+		return fmt.Sprintf("Your API credentials%s expire on %s. Please refresh them by running: %s, or set %s in the config file.",
+			profileSuffix(client.Profile),
+			expiry.Format("02 Jan 2006 15:04:05PM"),
+			lightBlueForeground.Render(refreshCmd),
+			lightBlueForeground.Render("auto_refresh_credentials: true"),
+		), nil
+	}
+
+	newCreds, _, err := client.Identity.SelfGenerateCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The credentials are stored before the uploads start, so that the new credentials
+	// are not lost if the scans fail.
+	if err = storeCredentials(homeDir, client.Profile, newCreds.ApiId, newCreds.ApiSecret); err != nil {
+		return "", err
+	}
+
+	if err = client.UpdateCredentials(newCreds.ApiId, newCreds.ApiSecret); err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Your API credentials%s were due to expire on %s and have been automatically re-generated. New Expiration Date: %s",
+		profileSuffix(client.Profile),
+		expiry.Format("02 Jan 2006 15:04:05PM"),
+		newCreds.ExpirationTs.Local().Format("02 Jan 2006 15:04:05PM"),
+	)
+
+	logToFile(msg)
+
+	return msg, nil
+}
+
+// profileSuffix returns the name of a profile to include in messages, or nothing if it is the default profile.
+func profileSuffix(profile string) string {
+	if profile == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (profile: %s)", profile)
+}
+
 // credsResultMsg contains all of the content for the self credential update method.
 type credsResultMsg struct {
 	err    error
@@ -62,13 +171,13 @@ type credsResultMsg struct {
 //
 //	verapack credential refresh
 type CredentialsRefreshModel struct {
-	result    credsResultMsg
-	homeDir   string
-	client    *veracode.Client
-	spinner   spinner.Model
-	errs      []error
-	state     int // 0:generating credentials,1:generating files,2:done (either successfully or not)
-	filesDone int
+	result  credsResultMsg
+	homeDir string
+	profile string
+	client  *veracode.Client
+	spinner spinner.Model
+	errs    []error
+	state   int // 0:generating credentials,1:generating files,2:done (either successfully or not)
 }
 
 // Init makes the initial call to the self generate credentials endpoint.
@@ -98,28 +207,19 @@ func (m CredentialsRefreshModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.state = 1
 
-		// start a batch of tasks to set the 2 credential files. They will return an anonymous struct with an error field.
-		cmds = append(cmds, tea.Batch(
-			func() tea.Msg {
-				return struct{ err error }{err: setCredentialsFile(m.homeDir, msg.result.ApiId, msg.result.ApiSecret)}
-			},
-			func() tea.Msg {
-				return struct{ err error }{err: setLegacyCredentialsFile(m.homeDir, msg.result.ApiId, msg.result.ApiSecret)}
-			},
-		))
+		// start the task to set the credential files. It will return an anonymous struct with an error field.
+		cmds = append(cmds, func() tea.Msg {
+			return struct{ err error }{err: storeCredentials(m.homeDir, m.profile, msg.result.ApiId, msg.result.ApiSecret)}
+		})
 
 	case struct{ err error }:
-		// when the anonymous struct msg is received, it means that one of the creds update tasks have completed.
-		m.filesDone += 1
-
+		// when the anonymous struct msg is received, it means that the creds update task has completed.
 		if msg.err != nil {
 			m.errs = append(m.errs, msg.err)
 		}
 
-		if m.filesDone == 2 {
-			m.state = 2 // set state to 2 indicating done. However, if there are errors in m.errs, error panel will be displayed.
-			return m, tea.Quit
-		}
+		m.state = 2 // set state to 2 indicating done. However, if there are errors in m.errs, error panel will be displayed.
+		return m, tea.Quit
 	}
 
 	var cmd tea.Cmd
@@ -153,10 +253,13 @@ func (m CredentialsRefreshModel) View() string {
 	return s
 }
 
-func NewCredentialsRefreshModel(client *veracode.Client, homeDir string) CredentialsRefreshModel {
+// NewCredentialsRefreshModel creates a CredentialsRefreshModel that re-generates the credentials of the provided profile.
+// If profile is empty, the default profile is used.
+func NewCredentialsRefreshModel(client *veracode.Client, homeDir, profile string) CredentialsRefreshModel {
 	return CredentialsRefreshModel{
 		client:  client,
 		homeDir: homeDir,
+		profile: profile,
 		spinner: spinner.New(defaultSpinnerOpts...),
 	}
 }
@@ -169,18 +272,21 @@ type CredentialsConfigureModel struct {
 	termWidth int
 	help      help.Model
 	homeDir   string
+	profile   string
 	spinner   spinner.Model
 	errs      []error
 	state     int // 0:waiting for user input,2:done (either successfully or not)
-	filesDone int
 }
 
-func NewCredentialsConfigureModel(credentialsTask CredentialsTask, homeDir string) CredentialsConfigureModel {
+// NewCredentialsConfigureModel creates a CredentialsConfigureModel that sets the credentials of the provided profile.
+// If profile is empty, the default profile is used.
+func NewCredentialsConfigureModel(credentialsTask CredentialsTask, homeDir, profile string) CredentialsConfigureModel {
 	return CredentialsConfigureModel{
 		CredentialsTask: credentialsTask,
 		help:            help.New(),
 		spinner:         spinner.New(defaultSpinnerOpts...),
 		homeDir:         homeDir,
+		profile:         profile,
 	}
 }
 
@@ -199,17 +305,13 @@ func (m CredentialsConfigureModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 	case struct{ err error }:
-		// when the anonymous struct msg is received, it means that one of the creds update tasks have completed.
-		m.filesDone += 1
-
+		// when the anonymous struct msg is received, it means that the creds update task has completed.
 		if msg.err != nil {
 			m.errs = append(m.errs, msg.err)
 		}
 
-		if m.filesDone == 2 {
-			m.state = 1 // set state to 1 indicating done. However, if there are errors in m.errs, error panel will be displayed.
-			return m, tea.Quit
-		}
+		m.state = 1 // set state to 1 indicating done. However, if there are errors in m.errs, error panel will be displayed.
+		return m, tea.Quit
 	}
 
 	var cmd tea.Cmd
@@ -220,14 +322,9 @@ func (m CredentialsConfigureModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.CredentialsTask = model.(CredentialsTask)
 
 		if m.state == 0 && m.CredentialsTask.isInputDone {
-			return m, tea.Batch(
-				func() tea.Msg {
-					return struct{ err error }{err: setCredentialsFile(m.homeDir, m.apiKey, m.apiSecret)}
-				},
-				func() tea.Msg {
-					return struct{ err error }{err: setLegacyCredentialsFile(m.homeDir, m.apiKey, m.apiSecret)}
-				},
-			)
+			return m, func() tea.Msg {
+				return struct{ err error }{err: storeCredentials(m.homeDir, m.profile, m.apiKey, m.apiSecret)}
+			}
 		}
 	}
 
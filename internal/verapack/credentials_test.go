@@ -0,0 +1,48 @@
+package verapack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanCreative/veracode-go/veracode"
+)
+
+func TestSetLegacyCredentialsFile(t *testing.T) {
+	homeDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(homeDir, ".veracode"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setLegacyCredentialsFile(homeDir, "", "default-id", "default-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setLegacyCredentialsFile(homeDir, "eu", "vera01ei-id", "eu-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setLegacyCredentialsFile(homeDir, "", "new-id", "new-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := veracode.GetProfiles(filepath.Join(homeDir, ".veracode", "credentials"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]veracode.Profile{
+		"default": {Name: "default", VeracodeApiKeyId: "new-id", VeracodeApiKeySecret: "new-secret"},
+		"eu":      {Name: "eu", VeracodeApiKeyId: "vera01ei-id", VeracodeApiKeySecret: "eu-secret"},
+	}
+
+	if len(profiles) != len(want) {
+		t.Fatalf("GetProfiles() = %v, want %v", profiles, want)
+	}
+
+	for name, profile := range want {
+		if profiles[name] != profile {
+			t.Errorf("profile %s = %v, want %v", name, profiles[name], profile)
+		}
+	}
+}
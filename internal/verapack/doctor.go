@@ -0,0 +1,399 @@
+package verapack
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/DanCreative/verapack/internal/components/multistagesetup"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type doctorStatus string
+
+const (
+	doctorStatusOk      doctorStatus = "ok"
+	doctorStatusWarning doctorStatus = "warning"
+	doctorStatusFailure doctorStatus = "failure"
+	doctorStatusSkipped doctorStatus = "skipped"
+
+	// minFreeDiskSpace is the minimum amount of free space, in bytes, in the work directory before the
+	// doctor warns the user. Cloning and packaging large applications can easily use a few GBs.
+	minFreeDiskSpace = 5 << 30
+)
+
+var (
+	javaVersionRegex = regexp.MustCompile(`version "([^"]+)"`)
+	gitVersionRegex  = regexp.MustCompile(`git version (\S+)`)
+
+	// supportedJavaVersions contains the major Java versions that are supported by the Java API wrapper.
+	supportedJavaVersions = map[int]bool{8: true, 11: true, 17: true}
+)
+
+// doctorResult is the outcome of a single doctor check.
+type doctorResult struct {
+	Check   string       `json:"check"`
+	Status  doctorStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+}
+
+// doctorCheck is a single diagnostic that is run by the doctor command.
+type doctorCheck struct {
+	name string
+	run  func() doctorResult
+}
+
+// doctorEnv contains the shared state for the doctor checks.
+type doctorEnv struct {
+	homeDir    string
+	configPath string
+	client     *veracode.Client
+	clientErr  error
+	apiKey     string
+	config     Config
+	configErr  error
+}
+
+// newDoctorEnv loads the credentials and the config file, and creates the API client that is shared by the
+// API checks. Errors are stored on the env so that they can be reported by the relevant checks.
+func newDoctorEnv(homeDir string) *doctorEnv {
+	e := &doctorEnv{
+		homeDir:    homeDir,
+		configPath: filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"),
+	}
+
+	var secret string
+
+	e.apiKey, secret, e.clientErr = loadProfileCredentials("")
+	if e.clientErr == nil {
+		e.client, e.clientErr = newVeracodeClient(e.apiKey, secret)
+	}
+
+	if _, e.configErr = os.Stat(e.configPath); e.configErr == nil {
+		e.config, e.configErr = ReadConfig(e.configPath)
+	}
+
+	return e
+}
+
+// doctorChecks returns all of the checks that are run by the doctor command, in order.
+func doctorChecks(e *doctorEnv) []doctorCheck {
+	return []doctorCheck{
+		{name: "Java version", run: checkJava},
+		{name: "git version", run: checkGit},
+		{name: "Veracode CLI", run: checkPackager},
+		{name: "Veracode Uploader", run: checkWrapper},
+		{name: "SCA agent", run: func() doctorResult { return checkScaAgent(e.homeDir) }},
+		{name: "API credentials", run: e.checkCredentials},
+		{name: "API reachability & TLS", run: e.checkConnectivity},
+		{name: "Config file", run: e.checkConfig},
+		{name: "Free disk space", run: checkDiskSpace},
+	}
+}
+
+// RunDoctor runs all of the checks without a UI and returns the results.
+func RunDoctor(homeDir string) []doctorResult {
+	checks := doctorChecks(newDoctorEnv(homeDir))
+	results := make([]doctorResult, len(checks))
+
+	for k, check := range checks {
+		results[k] = check.run()
+		results[k].Check = check.name
+	}
+
+	return results
+}
+
+// WriteDoctorJSON runs all of the checks and writes the results to w as JSON. It returns an error
+// if any of the checks failed.
+func WriteDoctorJSON(homeDir string, w io.Writer) error {
+	results := RunDoctor(homeDir)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(results); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Status == doctorStatusFailure {
+			return errors.New("one or more doctor checks failed")
+		}
+	}
+
+	return nil
+}
+
+// DoctorTasks wraps the doctor checks in [multistagesetup.SetupTask]s, so that the results can be
+// rendered with the same UI as the setup.
+func DoctorTasks(homeDir string) []multistagesetup.SetupTask {
+	checks := doctorChecks(newDoctorEnv(homeDir))
+	tasks := make([]multistagesetup.SetupTask, len(checks))
+
+	for k, check := range checks {
+		tasks[k] = multistagesetup.NewSetupTask(check.name, NewSimpleTask(func(values map[string]any) tea.Cmd {
+			return func() tea.Msg {
+				r := check.run()
+
+				switch r.Status {
+				case doctorStatusWarning:
+					return multistagesetup.NewWarningTaskResult(r.Message, nil)
+				case doctorStatusFailure:
+					return multistagesetup.NewFailedTaskResult("", errors.New(r.Message), nil)
+				case doctorStatusSkipped:
+					return multistagesetup.NewSkippedTaskResult(r.Message, nil)
+				default:
+					return multistagesetup.NewSuccessfulTaskResult(r.Message, nil)
+				}
+			}
+		}))
+	}
+
+	return tasks
+}
+
+// parseJavaMajorVersion parses the output of "java -version" and returns the full and major version.
+//
+// Java 8 and older report their version as "1.8.0_381", newer versions as "17.0.2".
+func parseJavaMajorVersion(out string) (string, int, error) {
+	match := javaVersionRegex.FindStringSubmatch(out)
+	if len(match) < 2 {
+		return "", 0, fmt.Errorf("could not determine the Java version from output: %s", strings.TrimSpace(out))
+	}
+
+	version := match[1]
+	parts := strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' || r == '-' || r == '+' })
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return version, 0, fmt.Errorf("could not parse Java version: %s", version)
+	}
+
+	if major == 1 && len(parts) > 1 {
+		major, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return version, 0, fmt.Errorf("could not parse Java version: %s", version)
+		}
+	}
+
+	return version, major, nil
+}
+
+func checkJava() doctorResult {
+	path, err := exec.LookPath("java")
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: "Java was not found on the path. You can either install Java version 8, 11 or 17."}
+	}
+
+	// java -version writes to stderr.
+	out, err := exec.Command(path, "-version").CombinedOutput()
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: fmt.Sprintf("%s\n%s", err, out)}
+	}
+
+	version, major, err := parseJavaMajorVersion(string(out))
+	if err != nil {
+		return doctorResult{Status: doctorStatusWarning, Message: err.Error()}
+	}
+
+	if !supportedJavaVersions[major] {
+		return doctorResult{Status: doctorStatusFailure, Message: fmt.Sprintf("Java %s is not supported by the Veracode Uploader. Please install Java version 8, 11 or 17.", version)}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: "version: " + version}
+}
+
+func checkGit() doctorResult {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: "git was not found on the path."}
+	}
+
+	out, err := exec.Command(path, "--version").CombinedOutput()
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: fmt.Sprintf("%s\n%s", err, out)}
+	}
+
+	match := gitVersionRegex.FindStringSubmatch(string(out))
+	if len(match) < 2 {
+		return doctorResult{Status: doctorStatusWarning, Message: "could not determine the git version"}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: "version: " + match[1]}
+}
+
+func checkPackager() doctorResult {
+	packagerPath := getPackagerLocation()
+
+	if _, err := os.Stat(packagerPath); err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: "the Veracode CLI is not installed, please run: verapack setup"}
+	}
+
+	version := GetLocalVersion(filepath.Join(packagerPath, "VERSION"))
+
+	// The scan commands add the install location to the path, but the CLI won't be available
+	// to the user outside of verapack unless it is on the path.
+	if _, err := exec.LookPath("veracode"); err != nil {
+		return doctorResult{Status: doctorStatusWarning, Message: fmt.Sprintf("version: %s, but %s is not on the path", version, packagerPath)}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: "version: " + version}
+}
+
+func checkWrapper() doctorResult {
+	wrapperPath := getWrapperLocation()
+
+	if _, err := os.Stat(filepath.Join(wrapperPath, "VeracodeJavaAPI.jar")); err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: "the Veracode Uploader is not installed, please run: verapack setup"}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: "version: " + GetLocalVersion(filepath.Join(wrapperPath, "VERSION"))}
+}
+
+func checkScaAgent(homeDir string) doctorResult {
+	if _, err := os.Stat(getScaAgentLocation(homeDir)); err != nil {
+		return doctorResult{Status: doctorStatusWarning, Message: "the SCA agent was not found, please run: verapack update"}
+	}
+
+	return doctorResult{Status: doctorStatusOk}
+}
+
+func (e *doctorEnv) checkCredentials() doctorResult {
+	if e.clientErr != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: e.clientErr.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	creds, _, err := e.client.Identity.SelfGetCredentials(ctx)
+	if err != nil {
+		var apiError veracode.Error
+		if errors.As(err, &apiError) && apiError.Code == 401 {
+			return doctorResult{Status: doctorStatusFailure, Message: "the API credentials are invalid or have expired, please run: verapack credentials configure"}
+		}
+
+		return doctorResult{Status: doctorStatusFailure, Message: err.Error()}
+	}
+
+	expiry := creds.ExpirationTs.Local()
+	message := "expires: " + expiry.Format("02 Jan 2006")
+
+	// The default is used if the config file can not be read, which is reported by the config check.
+	days := defaultCredentialExpiryWarningDays
+	if e.configErr == nil {
+		days = *e.config.CredentialExpiryWarningDays
+	}
+
+	if credentialsExpireWithin(expiry, days) {
+		return doctorResult{Status: doctorStatusWarning, Message: message + ", please run: verapack credentials refresh"}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: message}
+}
+
+func (e *doctorEnv) checkConnectivity() doctorResult {
+	if e.clientErr != nil {
+		return doctorResult{Status: doctorStatusSkipped, Message: "requires valid API credentials"}
+	}
+
+	region, err := veracode.GetRegionFromCredentials(e.apiKey)
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: err.Error()}
+	}
+
+	restURL, _ := url.Parse(region["rest"])
+
+	issuer, err := getCertificateIssuer(restURL.Host)
+	if err != nil {
+		var unknownAuthority x509.UnknownAuthorityError
+		if errors.As(err, &unknownAuthority) {
+			return doctorResult{Status: doctorStatusFailure, Message: fmt.Sprintf("the certificate for %s was issued by an untrusted authority: '%s'. This is usually caused by a TLS interception proxy whose root certificate is not installed.", restURL.Host, unknownAuthority.Cert.Issuer.CommonName)}
+		}
+
+		return doctorResult{Status: doctorStatusFailure, Message: fmt.Sprintf("could not connect to %s: %s", restURL.Host, err)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.Healthcheck.GetStatus(ctx)
+	if err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: err.Error()}
+	}
+
+	if resp.StatusCode != 200 {
+		return doctorResult{Status: doctorStatusWarning, Message: fmt.Sprintf("%s is reachable, but reported status: %s", restURL.Host, resp.Status)}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: fmt.Sprintf("%s, certificate issued by: %s", restURL.Host, issuer)}
+}
+
+// getCertificateIssuer opens a TLS connection to the host and returns the issuer of the root
+// certificate in the verified chain.
+func getCertificateIssuer(host string) (string, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 15 * time.Second}, "tcp", host+":443", &tls.Config{})
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	chains := conn.ConnectionState().VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return "", errors.New("no verified certificate chain")
+	}
+
+	chain := chains[0]
+
+	return chain[len(chain)-1].Subject.CommonName, nil
+}
+
+func (e *doctorEnv) checkConfig() doctorResult {
+	if errors.Is(e.configErr, fs.ErrNotExist) {
+		return doctorResult{Status: doctorStatusFailure, Message: "the config file does not exist, please run: verapack setup"}
+	}
+
+	if e.configErr != nil {
+		// The width does not really matter here, the message is rendered inside of the stage block.
+		return doctorResult{Status: doctorStatusFailure, Message: rawRenderErrors(500, e.configErr)}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: fmt.Sprintf("%d application(s)", len(e.config.Applications))}
+}
+
+func checkDiskSpace() doctorResult {
+	workDir := filepath.Join(os.TempDir(), "verapack", "workdir")
+
+	if err := os.MkdirAll(workDir, 0600); err != nil {
+		return doctorResult{Status: doctorStatusFailure, Message: err.Error()}
+	}
+
+	free, err := getFreeDiskSpace(workDir)
+	if err != nil {
+		return doctorResult{Status: doctorStatusWarning, Message: err.Error()}
+	}
+
+	message := fmt.Sprintf("%.1f GB free in %s", float64(free)/(1<<30), workDir)
+
+	if free < minFreeDiskSpace {
+		return doctorResult{Status: doctorStatusWarning, Message: message}
+	}
+
+	return doctorResult{Status: doctorStatusOk, Message: message}
+}
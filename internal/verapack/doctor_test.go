@@ -0,0 +1,49 @@
+package verapack
+
+import "testing"
+
+func TestParseJavaMajorVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		out         string
+		wantVersion string
+		wantMajor   int
+		wantErr     bool
+	}{
+		{
+			name:        "java 8",
+			out:         "java version \"1.8.0_381\"\nJava(TM) SE Runtime Environment (build 1.8.0_381-b09)",
+			wantVersion: "1.8.0_381",
+			wantMajor:   8,
+		},
+		{
+			name:        "openjdk 17",
+			out:         "openjdk version \"17.0.2\" 2022-01-18\nOpenJDK Runtime Environment (build 17.0.2+8-86)",
+			wantVersion: "17.0.2",
+			wantMajor:   17,
+		},
+		{
+			name:        "openjdk 21 without minor version",
+			out:         "openjdk version \"21\" 2023-09-19",
+			wantVersion: "21",
+			wantMajor:   21,
+		},
+		{
+			name:    "unexpected output",
+			out:     "Error: could not find java.dll",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, major, err := parseJavaMajorVersion(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJavaMajorVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if version != tt.wantVersion || major != tt.wantMajor {
+				t.Errorf("parseJavaMajorVersion() = %s, %d, want %s, %d", version, major, tt.wantVersion, tt.wantMajor)
+			}
+		})
+	}
+}
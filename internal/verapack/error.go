@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 
 	"github.com/DanCreative/veracode-go/veracode"
 	"github.com/charmbracelet/lipgloss"
@@ -65,6 +66,8 @@ func rawRenderErrors(width int, errs ...error) string {
 	// Adjust the width for the bullet point: "✗  "
 	msgStyle := lipgloss.NewStyle().Width(width - 3)
 
+	errs = flattenErrors(errs)
+
 	for k, err := range errs {
 		var validateErrs validator.ValidationErrors
 		var apiError veracode.Error
@@ -76,6 +79,24 @@ func rawRenderErrors(width int, errs ...error) string {
 					msg = fmt.Sprintf("config validation error at %s: field is required", e.Namespace())
 				case "required_without":
 					msg = fmt.Sprintf("config validation error at %s: either field '%s' or field '%s' is required", e.Namespace(), e.Field(), e.Param())
+				case "version_template":
+					msg = fmt.Sprintf("config validation error at %s: '%s' is not a valid version template", e.Namespace(), e.Value())
+				case "version_unique":
+					msg = fmt.Sprintf("config validation error at %s: version template '%s' could collide with an existing build name, because it renders the same name for every scan of a commit. Please add the {{.Date}} placeholder", e.Namespace(), e.Value())
+				case "glob":
+					msg = fmt.Sprintf("config validation error at %s: '%s' is not a valid glob pattern", e.Namespace(), e.Value())
+				case "repo_path":
+					msg = fmt.Sprintf("config validation error at %s: '%s' must be a relative path inside the repository", e.Namespace(), e.Value())
+				case "excluded_with":
+					msg = fmt.Sprintf("config validation error at %s: field can not be used together with field '%s'", e.Namespace(), e.Param())
+				case "excluded_without":
+					msg = fmt.Sprintf("config validation error at %s: field can only be used together with field '%s'", e.Namespace(), e.Param())
+				case "wrapper_args":
+					msg = fmt.Sprintf("config validation error at %s: %s", e.Namespace(), e.Param())
+				case "lifecycle_stage":
+					msg = fmt.Sprintf("config validation error at %s: field value must be one of: [%s]", e.Namespace(), strings.Join(lifecycleStages, ", "))
+				case "excludesall":
+					msg = fmt.Sprintf("config validation error at %s: '%s' can not contain any of the characters: '%s'", e.Namespace(), e.Value(), strings.ReplaceAll(e.Param(), "0x2C", ","))
 				case "oneof":
 					msg = fmt.Sprintf("config validation error at %s: field value must be one of: [%v]", e.Namespace(), e.Param())
 				case "gt":
@@ -95,6 +116,10 @@ func rawRenderErrors(width int, errs ...error) string {
 					msg = fmt.Sprintf("config validation error at %s: directory '%s' does not exist", e.Namespace(), e.Value())
 				case "file|dir":
 					msg = fmt.Sprintf("config validation error at %s: file or directory '%s' does not exist", e.Namespace(), e.Value())
+				case "url":
+					msg = fmt.Sprintf("config validation error at %s: '%s' is not a valid URL", e.Namespace(), e.Value())
+				case "gte":
+					msg = fmt.Sprintf("config validation error at %s: field must be greater or equal to: '%s'", e.Namespace(), e.Param())
 				case "min":
 					msg = fmt.Sprintf("config validation error at %s: field must be greater or equal to: '%s'", e.Namespace(), e.Param())
 				case "max":
@@ -152,6 +177,47 @@ func rawRenderErrors(width int, errs ...error) string {
 	return r
 }
 
+// flattenErrors replaces the errors that were joined with errors.Join with the joined errors, so that each of
+// them is rendered as a separate bullet point.
+func flattenErrors(errs []error) []error {
+	r := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			r = append(r, flattenErrors(joined.Unwrap())...)
+		} else if err != nil {
+			r = append(r, err)
+		}
+	}
+
+	return r
+}
+
+// renderNotice renders a single message with a title for the user, in the same style as [renderErrors].
+func renderNotice(title, msg string) string {
+	width, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		width = 500
+	}
+
+	width = int(float64(width) * 0.6)
+
+	return lipgloss.NewStyle().
+		PaddingLeft(1).
+		PaddingRight(1).
+		PaddingBottom(1).
+		MarginLeft(2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(darkGray).
+		Render(
+			lipgloss.NewStyle().
+				Padding(0, 0, 1, 0).
+				AlignHorizontal(lipgloss.Center).
+				Underline(true).Render(title)+"\n"+
+				lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Foreground(orange).Render("⚠")+"  ", lipgloss.NewStyle().Width(width-3).Render(msg)),
+		) + "\n"
+}
+
 func RenderErrors(errs ...error) string {
 	return renderErrors(errs...)
 }
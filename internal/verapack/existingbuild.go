@@ -0,0 +1,189 @@
+package verapack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DanCreative/veracode-go/veracode"
+)
+
+// ExistingBuildAction determines what happens if the application or sandbox has an incomplete build when a new
+// build is uploaded.
+type ExistingBuildAction string
+
+const (
+	ExistingBuildFail   ExistingBuildAction = "fail"   // Fail the upload with a description of the existing build.
+	ExistingBuildWait   ExistingBuildAction = "wait"   // Wait for the existing build to complete.
+	ExistingBuildDelete ExistingBuildAction = "delete" // Delete the existing build.
+
+	// buildStatusResultsReady is the status of a build that is complete.
+	buildStatusResultsReady = "Results Ready"
+
+	// stuckBuildPolls is the number of polls after which a build with a status in pausedBuildStatuses is stuck.
+	stuckBuildPolls = 3
+)
+
+var (
+	errExistingBuild = errors.New("incomplete build exists")
+
+	// stuckBuildStatuses are the statuses of incomplete builds that do not complete without user interaction.
+	stuckBuildStatuses = []string{"Incomplete", "Prescan Failed", "Pre-Scan Failed", "No Modules Defined"}
+
+	// pausedBuildStatuses are the statuses of incomplete builds that only complete without user interaction if
+	// the scan was started automatically. A successful prescan waits for the modules to be selected otherwise.
+	// The build is stuck if its status does not change within stuckBuildPolls.
+	pausedBuildStatuses = []string{"Pre-Scan Success"}
+
+	// noBuildsMessages are the messages of the error that the XML API returns if the application or sandbox does
+	// not have any builds, in lower case.
+	noBuildsMessages = []string{"could not find a build", "no build exists", "no builds"}
+)
+
+// existingBuild is the most recent build of an application or sandbox.
+type existingBuild struct {
+	id      int
+	version string
+	status  string
+}
+
+func (b existingBuild) String() string {
+	return fmt.Sprintf("'%s' (buildId=%d) with status '%s'", b.version, b.id, b.status)
+}
+
+// getIncompleteBuild returns the most recent build of the application or sandbox, and whether it is incomplete.
+// An application without any builds does not have an incomplete build.
+func getIncompleteBuild(ctx context.Context, client *veracode.Client, options Options) (existingBuild, bool, error) {
+	bi, _, err := client.UploadXML.GetBuildInfo(ctx, veracode.BuildInfoOptions{AppId: options.AppId, SandboxId: options.SandboxId})
+	if err != nil {
+		// The XML API returns an error document if the application or sandbox does not have any builds. Other
+		// errors, e.g. missing permissions or an invalid sandbox, are returned.
+		var verr veracode.Error
+		if errors.As(err, &verr) && isNoBuildsError(verr) {
+			return existingBuild{}, false, nil
+		}
+
+		return existingBuild{}, false, err
+	}
+
+	build := existingBuild{version: bi.Build.Version, status: bi.Build.AnalysisUnit.Status}
+	build.id, _ = strconv.Atoi(bi.BuildId)
+
+	return build, build.id != 0 && build.status != buildStatusResultsReady, nil
+}
+
+// isNoBuildsError returns whether the error of the XML API means that the application or sandbox does not have
+// any builds, see [noBuildsMessages].
+func isNoBuildsError(verr veracode.Error) bool {
+	for _, message := range verr.Messages {
+		message = strings.ToLower(message)
+
+		if slices.ContainsFunc(noBuildsMessages, func(s string) bool { return strings.Contains(message, s) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteBuild deletes the most recent build of the application or sandbox.
+//
+// Documentation Reference: https://docs.veracode.com/r/r_deletebuild
+func deleteBuild(ctx context.Context, client *veracode.Client, options Options) error {
+	req, err := client.NewRequest(ctx, "/api/5.0/deletebuild.do", http.MethodPost, nil, true)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/xml")
+	req.URL.RawQuery = veracode.QueryEncode(veracode.BuildInfoOptions{AppId: options.AppId, SandboxId: options.SandboxId})
+
+	var result veracode.BuildList
+
+	_, err = client.Do(req, &result)
+	return err
+}
+
+// handleExistingBuild checks whether the application or sandbox has an incomplete build before a new build is
+// uploaded, because the API wrapper can not upload a new build until it is complete. What happens to the
+// incomplete build is determined by [Options].OnExistingBuild. It returns the log output and any error.
+func handleExistingBuild(ctx context.Context, client *veracode.Client, options Options, writer io.Writer) (string, error) {
+	if options.AppId == 0 {
+		id, _, err := getApplicationIdentifiers(ctx, client, options.AppName)
+		if err != nil {
+			if errors.Is(err, errApplicationNotFound) {
+				// The application profile is created by the upload, therefore it does not have any builds.
+				return "", nil
+			}
+
+			return err.Error(), err
+		}
+
+		options.AppId = id
+	}
+
+	build, incomplete, err := getIncompleteBuild(ctx, client, options)
+	if err != nil {
+		return err.Error(), err
+	}
+
+	if !incomplete {
+		return "", nil
+	}
+
+	switch options.OnExistingBuild {
+	case ExistingBuildDelete:
+		fmt.Fprintf(writer, "Deleting the incomplete build %s.\n", build)
+
+		if err = deleteBuild(ctx, client, options); err != nil {
+			return fmt.Sprintf("The incomplete build %s could not be deleted: %s", build, err), err
+		}
+
+		return fmt.Sprintf("Deleted the incomplete build %s.\n", build), nil
+
+	case ExistingBuildWait:
+		fmt.Fprintf(writer, "Waiting for the incomplete build %s to complete.\n", build)
+
+		timeout := time.After(time.Duration(options.ScanTimeout) * time.Minute)
+
+		// unchanged is the number of polls in which the build did not change.
+		var unchanged int
+
+		for incomplete {
+			if slices.Contains(stuckBuildStatuses, build.status) || (slices.Contains(pausedBuildStatuses, build.status) && unchanged >= stuckBuildPolls) {
+				return fmt.Sprintf("The incomplete build %s will not complete without user interaction. Complete or delete it on the platform, or set on_existing_build to delete.", build), errExistingBuild
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err().Error(), ctx.Err()
+			case <-timeout:
+				return fmt.Sprintf("The incomplete build %s did not complete within the timeout set: %d min", build, options.ScanTimeout), errExistingBuild
+			case <-time.After(time.Duration(options.ScanPollingInterval) * time.Second):
+			}
+
+			previous := build
+
+			if build, incomplete, err = getIncompleteBuild(ctx, client, options); err != nil {
+				return err.Error(), err
+			}
+
+			if build == previous {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+
+		return fmt.Sprintf("The existing build %s completed.\n", build), nil
+
+	default:
+		return fmt.Sprintf("The application has an incomplete build %s. A new build can only be uploaded once it has completed. Set on_existing_build to wait for it to complete, or to delete it.", build), errExistingBuild
+	}
+}
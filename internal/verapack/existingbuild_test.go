@@ -0,0 +1,102 @@
+package verapack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DanCreative/veracode-go/veracode"
+)
+
+// roundTripperFunc is an [http.RoundTripper] that is a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestClient returns a client whose requests are served by handler, instead of the platform.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *veracode.Client {
+	t.Helper()
+
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		resp := rec.Result()
+		resp.Request = req
+
+		return resp, nil
+	})
+
+	client, err := veracode.NewClient(&http.Client{Transport: transport}, "0123", "0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+// xmlHandler returns a handler that responds with the XML document.
+func xmlHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		io.WriteString(w, body)
+	}
+}
+
+func TestGetIncompleteBuild(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantIncomplete bool
+		wantErr        bool
+	}{
+		{name: "no builds", body: `<error>Could not find a build for application=1</error>`},
+		{name: "access denied", body: `<error>Access denied.</error>`, wantErr: true},
+		{name: "results ready", body: `<buildinfo build_id="2"><build version="v1"><analysis_unit status="Results Ready"/></build></buildinfo>`},
+		{name: "scan in process", body: `<buildinfo build_id="2"><build version="v1"><analysis_unit status="Scan In Process"/></build></buildinfo>`, wantIncomplete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, xmlHandler(tt.body))
+
+			_, incomplete, err := getIncompleteBuild(context.Background(), client, Options{AppId: 1})
+			if (err != nil) != tt.wantErr || incomplete != tt.wantIncomplete {
+				t.Errorf("getIncompleteBuild() = %v, %v, want %v, wantErr %v", incomplete, err, tt.wantIncomplete, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleExistingBuildWait(t *testing.T) {
+	const prescan = `<buildinfo build_id="2"><build version="v1"><analysis_unit status="Pre-Scan Success"/></build></buildinfo>`
+
+	tests := []struct {
+		name    string
+		bodies  []string // The build info of each poll. The last one is repeated.
+		wantErr error
+	}{
+		{name: "prescan completes", bodies: []string{prescan, prescan, `<buildinfo build_id="2"><build version="v1"><analysis_unit status="Results Ready"/></build></buildinfo>`}},
+		{name: "prescan does not change", bodies: []string{prescan}, wantErr: errExistingBuild},
+		{name: "prescan failed", bodies: []string{`<buildinfo build_id="2"><build version="v1"><analysis_unit status="Pre-Scan Failed"/></build></buildinfo>`}, wantErr: errExistingBuild},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls int
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				xmlHandler(tt.bodies[min(polls, len(tt.bodies)-1)])(w, r)
+				polls++
+			})
+
+			options := Options{AppId: 1, OnExistingBuild: ExistingBuildWait, ScanTimeout: 1}
+
+			if _, err := handleExistingBuild(context.Background(), client, options, io.Discard); !errors.Is(err, tt.wantErr) {
+				t.Errorf("handleExistingBuild() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
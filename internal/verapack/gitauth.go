@@ -0,0 +1,147 @@
+package verapack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultGitUsername is the username that is sent with an HTTPS token, if [GitAuth].Username is not set.
+	// Most git hosts ignore the username when a token is used, but it can not be empty.
+	defaultGitUsername = "x-access-token"
+
+	// gitAskPassEnv is set in the environment of git, to tell verapack that it is run as the GIT_ASKPASS
+	// program, see [RunGitAskPass].
+	gitAskPassEnv   = "VERAPACK_GIT_ASKPASS"
+	gitUsernameEnv  = "VERAPACK_GIT_USERNAME"
+	gitTokenEnv     = "VERAPACK_GIT_TOKEN"
+	gitAskPassValue = "1"
+)
+
+// GitAuth contains the credentials that are used to clone an application's repository. Either an HTTPS token,
+// read from an environment variable or a file, or an SSH private key can be set.
+type GitAuth struct {
+	Username   string `yaml:"username"`                                                  // Username to send with the token. The default value is "x-access-token".
+	TokenEnv   string `yaml:"token_env" validate:"excluded_with=TokenFile SSHKey"`       // Name of the environment variable that contains the HTTPS token.
+	TokenFile  string `yaml:"token_file" validate:"omitempty,file,excluded_with=SSHKey"` // Path to a file that contains the HTTPS token.
+	SSHKey     string `yaml:"ssh_key" validate:"omitempty,file"`                         // Path to the SSH private key.
+	KnownHosts string `yaml:"known_hosts" validate:"omitempty,file,excluded_without=SSHKey"`
+}
+
+// Token returns the HTTPS token from the environment variable or the file. If neither is set, an empty
+// token is returned.
+func (a GitAuth) Token() (string, error) {
+	switch {
+	case a.TokenEnv != "":
+		token := os.Getenv(a.TokenEnv)
+		if token == "" {
+			return "", fmt.Errorf("git_auth: environment variable %s is not set", a.TokenEnv)
+		}
+
+		return token, nil
+	case a.TokenFile != "":
+		content, err := os.ReadFile(a.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("git_auth: could not read the token file: %w", err)
+		}
+
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return "", fmt.Errorf("git_auth: token file %s is empty", a.TokenFile)
+		}
+
+		return token, nil
+	}
+
+	return "", nil
+}
+
+// Env returns the environment variables that make git use the credentials. They are only set on the git
+// processes of the application, so that the credentials do not leak to other applications.
+//
+// An HTTPS token is provided by verapack itself, which is set as the GIT_ASKPASS program. An SSH key is
+// provided with GIT_SSH_COMMAND.
+func (a GitAuth) Env() ([]string, error) {
+	// Fail instead of waiting for input, if git needs credentials that are not provided.
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+
+	if a.SSHKey != "" {
+		command := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes -o BatchMode=yes", shellQuote(a.SSHKey))
+		if a.KnownHosts != "" {
+			command += fmt.Sprintf(" -o UserKnownHostsFile=%s -o StrictHostKeyChecking=yes", shellQuote(a.KnownHosts))
+		}
+
+		return append(env, "GIT_SSH_COMMAND="+command), nil
+	}
+
+	token, err := a.Token()
+	if err != nil || token == "" {
+		return env, err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	username := a.Username
+	if username == "" {
+		username = defaultGitUsername
+	}
+
+	env = append(env,
+		"GIT_ASKPASS="+exe,
+		gitAskPassEnv+"="+gitAskPassValue,
+		gitUsernameEnv+"="+username,
+		gitTokenEnv+"="+token,
+	)
+
+	// Credential helpers are asked before GIT_ASKPASS, therefore they are disabled to make sure the
+	// token is used.
+	return append(env, gitConfigEnv("credential.helper", "")...), nil
+}
+
+// gitConfigEnv returns the environment variables that set the git config key to value. The entry is added after
+// the GIT_CONFIG_COUNT entries that are already set in the environment, e.g. by CI, so that they are kept.
+func gitConfigEnv(key, value string) []string {
+	n, err := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	if err != nil || n < 0 {
+		n = 0
+	}
+
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", n, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, value),
+	}
+}
+
+// shellQuote quotes a path for GIT_SSH_COMMAND, which is run by a shell, also on Windows.
+func shellQuote(p string) string {
+	return "'" + strings.ReplaceAll(filepath.ToSlash(p), "'", `'\''`) + "'"
+}
+
+// IsGitAskPass returns whether verapack was started by git as the GIT_ASKPASS program.
+func IsGitAskPass() bool {
+	return os.Getenv(gitAskPassEnv) == gitAskPassValue
+}
+
+// RunGitAskPass answers the credential prompt of git with the username or the token from the environment,
+// see [GitAuth].Env. It returns the exit code.
+func RunGitAskPass(args []string, w io.Writer) int {
+	if len(args) == 0 {
+		return 1
+	}
+
+	if strings.HasPrefix(strings.ToLower(args[0]), "username") {
+		fmt.Fprintln(w, os.Getenv(gitUsernameEnv))
+	} else {
+		fmt.Fprintln(w, os.Getenv(gitTokenEnv))
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+package verapack
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// matchGlob reports whether name matches the glob pattern. Both are slash-separated paths. In addition
+// to the syntax of [path.Match], a "**" path segment matches zero or more segments, e.g. "**/*.jar"
+// matches a jar file in any directory. An invalid pattern does not match anything.
+func matchGlob(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try to match the rest of the pattern at every remaining segment.
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// matchAnyGlob reports whether the relative path rel matches any of the glob patterns.
+func matchAnyGlob(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range patterns {
+		if matchGlob(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateGlob is the validation function for validating that a glob pattern has a valid syntax.
+func validateGlob(fl validator.FieldLevel) bool {
+	for _, segment := range strings.Split(filepath.ToSlash(fl.Field().String()), "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
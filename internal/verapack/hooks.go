@@ -0,0 +1,178 @@
+package verapack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/DanCreative/verapack/internal/components/reportcard"
+)
+
+// defaultHookTimeout is the default number of seconds after which a hook is stopped.
+const defaultHookTimeout = 600
+
+var (
+	errHookErr = errors.New("hook error")
+
+	// buildIdPattern matches the ID of the new build in the output of the API wrapper.
+	buildIdPattern = regexp.MustCompile(`analysis id of the new analysis is "(\d+)"`)
+)
+
+// Hooks contains the shell commands that are run before or after the tasks of an application. The commands
+// are run with cmd on Windows, and sh on other platforms. See [hookEnv] for the environment variables that
+// are available to the commands.
+type Hooks struct {
+	PrePackage  string `yaml:"pre_package"`              // Run in the source directory, before the auto-packager.
+	PostPackage string `yaml:"post_package"`             // Run in the source directory, after the auto-packager.
+	PreUpload   string `yaml:"pre_upload"`               // Run before the artefacts are uploaded.
+	PostScan    string `yaml:"post_scan"`                // Run after the scan has been submitted, or completed if the result is awaited.
+	OnFailure   string `yaml:"on_failure"`               // Run if any of the tasks of the application failed.
+	Timeout     int    `yaml:"timeout" validate:"gte=0"` // Number of seconds after which a hook is stopped. The default value is 600.
+}
+
+// timeout returns the duration after which a hook is stopped.
+func (h Hooks) timeout() time.Duration {
+	if h.Timeout == 0 {
+		return defaultHookTimeout * time.Second
+	}
+
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// hookEnv contains the state of an application that is passed to its hooks as environment variables.
+type hookEnv struct {
+	appName       string
+	scanType      ScanType
+	workdir       string   // Directory that contains the clone and the packaged artefacts.
+	sourceDir     string   // Directory that is packaged.
+	artefactPaths []string // Artefacts that are uploaded.
+	version       string   // Name of the build.
+	buildId       string   // ID of the build, once it has been created.
+	result        string   // Result of the application: "pass" or "fail" if the result was awaited, "submitted" if not, or "failure".
+}
+
+// environ returns the environment variables of the hook.
+func (e hookEnv) environ(hook string) []string {
+	return []string{
+		"VERAPACK_HOOK=" + hook,
+		"VERAPACK_APP_NAME=" + e.appName,
+		"VERAPACK_SCAN_TYPE=" + string(e.scanType),
+		"VERAPACK_WORKDIR=" + e.workdir,
+		"VERAPACK_SOURCE_DIR=" + e.sourceDir,
+		"VERAPACK_ARTEFACT_PATHS=" + strings.Join(e.artefactPaths, string(os.PathListSeparator)),
+		"VERAPACK_VERSION=" + e.version,
+		"VERAPACK_BUILD_ID=" + e.buildId,
+		"VERAPACK_RESULT=" + e.result,
+	}
+}
+
+// dir returns the working directory of the hook: the source directory if it exists, otherwise the workdir if it
+// exists. If neither exists, e.g. after they have been cleaned up, the hook is run in the current directory.
+func (e hookEnv) dir() string {
+	for _, dir := range []string{e.sourceDir, e.workdir} {
+		if info, err := os.Stat(dir); dir != "" && err == nil && info.IsDir() {
+			return filepath.Clean(dir)
+		}
+	}
+
+	return ""
+}
+
+// parseBuildId returns the ID of the new build from the output of the API wrapper, or an empty string if
+// it could not be found.
+func parseBuildId(out string) string {
+	if m := buildIdPattern.FindStringSubmatch(out); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+// runHook runs the hook command with a timeout and returns the log output and any error.
+//
+// writer can optionally be provided to write log output to an additional location.
+func runHook(command string, timeout time.Duration, dir string, env []string, writer io.Writer) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, command)
+	cmd.Dir = dir
+	cmd.Env = withEnv(env)
+	// Processes that were started by the hook can keep the output pipes open after it was stopped.
+	cmd.WaitDelay = 5 * time.Second
+
+	var outBuffer bytes.Buffer
+
+	if writer != nil {
+		cmd.Stderr = io.MultiWriter(&outBuffer, writer)
+		cmd.Stdout = io.MultiWriter(&outBuffer, writer)
+	} else {
+		cmd.Stderr, cmd.Stdout = &outBuffer, &outBuffer
+	}
+
+	err := cmd.Run()
+	out := outBuffer.String()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return out + fmt.Sprintf("\nthe hook did not complete within the timeout: %s", timeout), errHookErr
+	}
+
+	if err != nil {
+		return err.Error() + "\n" + out, errHookErr
+	}
+
+	return out, nil
+}
+
+// redactor replaces secrets in output, see [lineCounterWriter].Redact.
+type redactor interface {
+	Redact(s string) string
+}
+
+// runHookTask runs a hook and reports the result in the hook's column of the report card. If writer is a
+// [redactor], the secrets are redacted from the output in the report card as well.
+func runHookTask(column, command string, hooks Hooks, env hookEnv, appId int, reporter reporter, writer io.Writer) error {
+	fmt.Fprintf(writer, "BEGIN (%s)\n", column)
+	out, err := runHook(command, hooks.timeout(), env.dir(), env.environ(column), writer)
+	fmt.Fprintf(writer, "END (%s)\n", column)
+
+	if r, ok := writer.(redactor); ok {
+		out = r.Redact(out)
+	}
+
+	status := reportcard.Success
+	if err != nil {
+		status = reportcard.Failure
+	}
+
+	reporter.Send(reportcard.TaskResultMsg{
+		Status: status,
+		Output: out,
+		Index:  appId,
+	})
+
+	return err
+}
+
+// onFailureHookTask runs the on_failure hook, if the application has one and failed is true. If failed is
+// false, the hook's task is skipped.
+func onFailureHookTask(options Options, failed bool, env hookEnv, appId int, reporter reporter, writer io.Writer) {
+	if !hasOnFailureHookTask(options) {
+		return
+	}
+
+	if !failed {
+		reporter.Send(reportcard.TaskResultMsg{Status: reportcard.Skip, Index: appId})
+		return
+	}
+
+	env.result = "failure"
+	runHookTask(columnOnFailure, options.Hooks.OnFailure, options.Hooks, env, appId, reporter, writer)
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package verapack
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// hookCommand returns the command that runs a hook with sh.
+//
+// The command is started in its own process group, so that the processes that it started are stopped
+// together with it when ctx is done.
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package verapack
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanCreative/verapack/internal/components/reportcard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRunHook(t *testing.T) {
+	env := hookEnv{appName: "App", buildId: "123"}
+
+	printEnv := "echo $VERAPACK_APP_NAME $VERAPACK_BUILD_ID"
+	// The command starts a child process, which has to be stopped together with the shell.
+	sleep := "sleep 5; echo done"
+	if runtime.GOOS == "windows" {
+		printEnv = "echo %VERAPACK_APP_NAME% %VERAPACK_BUILD_ID%"
+		sleep = "ping -n 6 127.0.0.1 >NUL"
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		want    string
+		wantErr bool
+	}{
+		{name: "environment", command: printEnv, timeout: time.Minute, want: "App 123"},
+		{name: "exit code", command: "exit 3", timeout: time.Minute, wantErr: true},
+		{name: "timeout", command: sleep, timeout: 100 * time.Millisecond, want: "timeout", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+
+			out, err := runHook(tt.command, tt.timeout, env.dir(), env.environ(columnPreUpload), nil)
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("runHook() took %s, want the child processes to be stopped after the timeout", elapsed)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runHook() error = %v, wantErr %v\n%s", err, tt.wantErr, out)
+			}
+
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("runHook() = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+// recordingReporter records the messages that are sent to the report card.
+type recordingReporter struct {
+	msgs []tea.Msg
+}
+
+func (r *recordingReporter) Send(msg tea.Msg) {
+	r.msgs = append(r.msgs, msg)
+}
+
+func TestRunHookTaskRedact(t *testing.T) {
+	var b bytes.Buffer
+
+	w := newLineCounterWriter(0, &b)
+	w.AddSecret("s3cr3t-token")
+
+	var r recordingReporter
+	if err := runHookTask(columnPostScan, "echo s3cr3t-token", Hooks{}, hookEnv{}, 0, &r, w); err != nil {
+		t.Fatalf("runHookTask() error = %v", err)
+	}
+
+	msg, _ := r.msgs[0].(reportcard.TaskResultMsg)
+	if out, _ := msg.Output.(string); strings.Contains(out, "s3cr3t") || !strings.Contains(out, redacted) {
+		t.Errorf("runHookTask() sent %#v, want the redacted output", r.msgs[0])
+	}
+
+	if strings.Contains(b.String(), "s3cr3t") {
+		t.Errorf("runHookTask() logged %q, want the redacted output", b.String())
+	}
+}
+
+func TestParseBuildId(t *testing.T) {
+	out := `[2025.06.01 19:12:29.707] Creating a new analysis with name "25.0.0".
+[2025.06.01 19:12:30.101] The analysis id of the new analysis is "45811234".`
+
+	if got := parseBuildId(out); got != "45811234" {
+		t.Errorf("parseBuildId() = %q, want %q", got, "45811234")
+	}
+}
@@ -0,0 +1,27 @@
+package verapack
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// hookCommand returns the command that runs a hook with cmd. The command line is set directly, because cmd
+// does not follow the quoting rules that are used to escape the arguments.
+//
+// When ctx is done, the whole process tree is stopped, because stopping cmd does not stop the processes that
+// it started, e.g. npm or msbuild.
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd.exe")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd.exe /S /C "` + command + `"`}
+	cmd.Cancel = func() error {
+		if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+			return cmd.Process.Kill()
+		}
+
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,435 @@
+package verapack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// maxIncludeDepth is the maximum number of nested includes.
+const maxIncludeDepth = 10
+
+// mainFileOnlyKeys are the fields that are read from the config file of verapack by themselves, e.g. by
+// [LoadMirrors], without its includes. They can not be set in included files, where they would be ignored.
+var mainFileOnlyKeys = []string{"mirrors", "credentials_store", "cache"}
+
+// commitShaRegex matches a full or abbreviated commit SHA.
+var commitShaRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// Include is a YAML file that is merged into the config file. It is either a local file, or a file in a git
+// repository, which is fetched into the clone cache.
+type Include struct {
+	// Path of the file. A relative path is relative to the directory of the file that includes it,
+	// or to the root of Repo.
+	Path string `yaml:"path" validate:"required"`
+	// URL of the git repository that contains the file.
+	Repo string `yaml:"repo"`
+	// Tag or commit SHA of Repo, which is required, because the included file can define hooks and build commands
+	// that are run on every machine that includes it. Branches are rejected, see [includeLoader].read.
+	Ref string `yaml:"ref" validate:"required_with=Repo,excluded_without=Repo"`
+}
+
+// source returns the name of the included file that is shown by the config show command.
+func (i Include) source() string {
+	if i.Repo == "" {
+		return i.Path
+	}
+
+	if i.Ref == "" {
+		return fmt.Sprintf("%s:%s", i.Repo, i.Path)
+	}
+
+	return fmt.Sprintf("%s@%s:%s", i.Repo, i.Ref, i.Path)
+}
+
+// sourcedValue is a value of a config file, together with the file it is from.
+type sourcedValue struct {
+	value  any
+	source string
+}
+
+// includeLoader loads the config files with their includes.
+type includeLoader struct {
+	gitPath  string
+	stack    []string // Sources of the files that are being loaded, to detect cycles.
+	included bool     // Whether any file was included.
+}
+
+// LoadConfigFile returns the config file with its includes merged into it, see [mergeConfigFiles]. It also returns
+// the file that every value is from, by the path of the value, e.g. $.applications[0].app_name.
+func LoadConfigFile(configPath string) ([]byte, map[string]string, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if absPath, err := filepath.Abs(configPath); err == nil {
+		configPath = absPath
+	}
+
+	l := &includeLoader{}
+
+	doc, err := l.load(content, Include{Path: configPath}, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources := make(map[string]string)
+	doc = stripSources(doc, "$", sources).(yaml.MapSlice)
+
+	// The config file is only changed if it includes other files.
+	if !l.included {
+		return content, sources, nil
+	}
+
+	merged, err := yaml.MarshalWithOptions(doc, yaml.IndentSequence(true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return merged, sources, nil
+}
+
+// load returns the config file with its includes merged into it. Every value is a [sourcedValue].
+//
+// The includes are merged in the order in which they are listed, followed by the file itself, so that
+// the file that includes them can override their values, see [mergeConfigFiles].
+func (l *includeLoader) load(content []byte, file Include, depth int) (yaml.MapSlice, error) {
+	source := file.source()
+
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("%s: includes are nested more than %d levels deep", source, maxIncludeDepth)
+	}
+
+	if slices.Contains(l.stack, source) {
+		return nil, fmt.Errorf("%s: include cycle: %s -> %s", source, strings.Join(l.stack, " -> "), source)
+	}
+
+	l.stack = append(l.stack, source)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	command := "verapack config migrate"
+	switch {
+	case file.Repo != "":
+		command = fmt.Sprintf("verapack config migrate --file %s, in a clone of %s", file.Path, file.Repo)
+	case depth > 0:
+		command += " --file " + source
+	}
+
+	if err := checkConfigVersion(content, source, command); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(content, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	var c struct {
+		Include []Include `yaml:"include"`
+	}
+
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	var merged yaml.MapSlice
+
+	for k, include := range c.Include {
+		if include.Path == "" {
+			return nil, fmt.Errorf("config validation error at %s: include[%d]: field 'path' is required", source, k)
+		}
+
+		if include.Ref != "" && include.Repo == "" {
+			return nil, fmt.Errorf("config validation error at %s: include[%d]: field 'ref' can only be used together with field 'repo'", source, k)
+		}
+
+		if include.Ref == "" && include.Repo != "" {
+			return nil, fmt.Errorf("config validation error at %s: include[%d]: field 'ref' is required together with field 'repo', pin the include to a tag or a commit SHA", source, k)
+		}
+
+		include = resolveInclude(file, include)
+		l.included = true
+
+		includeContent, err := l.read(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include[%d]: %w", source, k, err)
+		}
+
+		includeDoc, err := l.load(includeContent, include, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		merged = mergeConfigFiles(merged, includeDoc)
+	}
+
+	if depth > 0 {
+		for _, item := range doc {
+			if key, _ := item.Key.(string); slices.Contains(mainFileOnlyKeys, key) {
+				return nil, fmt.Errorf("config validation error at %s: field '%s' can only be set in the config file of verapack, not in an included file", source, key)
+			}
+		}
+	}
+
+	doc = slices.DeleteFunc(doc, func(item yaml.MapItem) bool { return item.Key == "include" })
+
+	return mergeConfigFiles(merged, withSource(doc, source, true).(yaml.MapSlice)), nil
+}
+
+// resolveInclude returns the include with its path relative to the file that includes it. Includes without a
+// repository that are in a repository, are in the same repository.
+func resolveInclude(parent, include Include) Include {
+	switch {
+	case include.Repo != "":
+		include.Path = path.Clean(strings.TrimPrefix(filepath.ToSlash(include.Path), "/"))
+	case parent.Repo != "":
+		include.Repo, include.Ref = parent.Repo, parent.Ref
+		include.Path = path.Join(path.Dir(parent.Path), filepath.ToSlash(include.Path))
+	default:
+		if rest, ok := strings.CutPrefix(include.Path, "~"); ok {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				include.Path = filepath.Join(homeDir, rest)
+			}
+		}
+
+		if !filepath.IsAbs(include.Path) {
+			include.Path = filepath.Join(filepath.Dir(parent.Path), include.Path)
+		}
+	}
+
+	return include
+}
+
+// read returns the content of the included file. Files in a repository are read from its mirror in the clone
+// cache, which is fetched first. If it can not be fetched, e.g. when offline, the file is read from the
+// mirror as it was last fetched. The ref of a repository must be a tag or a commit SHA, so that the included
+// file, which can define the commands that are run, only changes when the including file is changed.
+func (l *includeLoader) read(include Include) ([]byte, error) {
+	if include.Repo == "" {
+		return os.ReadFile(include.Path)
+	}
+
+	if l.gitPath == "" {
+		gitPath, err := exec.LookPath("git")
+		if err != nil {
+			return nil, fmt.Errorf("git is required to include files from a repository: %w", err)
+		}
+
+		l.gitPath = gitPath
+	}
+
+	mirrorPath, unlock, out, err := updateMirror(l.gitPath, nil, include.Repo, nil)
+	if err != nil && mirrorPath == "" {
+		return nil, fmt.Errorf("repository '%s' could not be fetched: %s", include.Repo, strings.TrimSpace(out))
+	}
+
+	defer unlock()
+
+	if err != nil {
+		logToFile(fmt.Sprintf("repository '%s' of an include could not be fetched, using the cached version: %s", include.Repo, strings.TrimSpace(out)))
+	}
+
+	ref := include.Ref
+
+	if !commitShaRegex.MatchString(ref) {
+		tag := exec.Command(l.gitPath, "-C", mirrorPath, "show-ref", "--verify", "--quiet", "refs/tags/"+ref)
+		tag.Env = withEnv(nil)
+
+		if tag.Run() != nil {
+			return nil, fmt.Errorf("ref '%s' of repository '%s' is not a tag or a commit SHA. Includes from a repository must be pinned, because their hooks and build commands are run on this machine", ref, include.Repo)
+		}
+	}
+
+	cmd := exec.Command(l.gitPath, "-C", mirrorPath, "show", ref+":"+include.Path)
+	cmd.Env = withEnv(nil)
+
+	content, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("file '%s' could not be read from '%s' at '%s': %s", include.Path, include.Repo, ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// withSource wraps the values of the config file in a [sourcedValue]. Maps are not wrapped, so that they
+// can be merged. The applications at the top level are not wrapped either, because they are merged by name.
+func withSource(value any, source string, top bool) any {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		r := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			if apps, ok := item.Value.([]any); ok && top && item.Key == "applications" {
+				wrapped := make([]any, 0, len(apps))
+				for _, app := range apps {
+					wrapped = append(wrapped, withSource(app, source, false))
+				}
+
+				r = append(r, yaml.MapItem{Key: item.Key, Value: wrapped})
+				continue
+			}
+
+			r = append(r, yaml.MapItem{Key: item.Key, Value: withSource(item.Value, source, false)})
+		}
+
+		return r
+
+	default:
+		return sourcedValue{value: value, source: source}
+	}
+}
+
+// mergeConfigFiles merges src into dst and returns the result:
+//
+//   - Maps, e.g. default and hooks, are merged key by key.
+//   - Applications are merged by app_name, case-insensitively. Applications that are not in dst are appended.
+//   - Other values, including lists, are replaced by the value in src.
+func mergeConfigFiles(dst, src yaml.MapSlice) yaml.MapSlice {
+	return mergeMaps(dst, src, true)
+}
+
+func mergeMaps(dst, src yaml.MapSlice, top bool) yaml.MapSlice {
+	r := slices.Clone(dst)
+
+	for _, item := range src {
+		k := slices.IndexFunc(r, func(i yaml.MapItem) bool { return i.Key == item.Key })
+		if k == -1 {
+			r = append(r, item)
+			continue
+		}
+
+		dstMap, dstIsMap := r[k].Value.(yaml.MapSlice)
+		srcMap, srcIsMap := item.Value.(yaml.MapSlice)
+		dstApps, dstIsApps := r[k].Value.([]any)
+		srcApps, srcIsApps := item.Value.([]any)
+
+		switch {
+		case dstIsMap && srcIsMap:
+			r[k].Value = mergeMaps(dstMap, srcMap, false)
+		case top && item.Key == "applications" && dstIsApps && srcIsApps:
+			r[k].Value = mergeApplications(dstApps, srcApps)
+		default:
+			r[k].Value = item.Value
+		}
+	}
+
+	return r
+}
+
+// mergeApplications merges the applications of src into the applications of dst with the same app_name.
+func mergeApplications(dst, src []any) []any {
+	r := slices.Clone(dst)
+
+	for _, app := range src {
+		name := appNameOf(app)
+
+		k := slices.IndexFunc(r, func(a any) bool { return name != "" && strings.EqualFold(appNameOf(a), name) })
+		if k == -1 {
+			r = append(r, app)
+			continue
+		}
+
+		dstApp, dstOk := r[k].(yaml.MapSlice)
+		srcApp, srcOk := app.(yaml.MapSlice)
+
+		if dstOk && srcOk {
+			// The app_name of the first file is kept, because the applications only differ in case.
+			srcApp = slices.DeleteFunc(slices.Clone(srcApp), func(item yaml.MapItem) bool { return item.Key == "app_name" })
+			r[k] = mergeMaps(dstApp, srcApp, false)
+		} else {
+			r[k] = app
+		}
+	}
+
+	return r
+}
+
+// appNameOf returns the app_name of the application, or an empty string if it is not set.
+func appNameOf(app any) string {
+	ms, _ := app.(yaml.MapSlice)
+
+	for _, item := range ms {
+		if item.Key != "app_name" {
+			continue
+		}
+
+		value := item.Value
+		if v, ok := value.(sourcedValue); ok {
+			value = v.value
+		}
+
+		name, _ := value.(string)
+		return name
+	}
+
+	return ""
+}
+
+// stripSources returns the value without the [sourcedValue] wrappers, and adds their sources to sources by the
+// path of the value.
+func stripSources(value any, p string, sources map[string]string) any {
+	switch v := value.(type) {
+	case sourcedValue:
+		sources[p] = v.source
+		return v.value
+
+	case yaml.MapSlice:
+		r := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			r = append(r, yaml.MapItem{Key: item.Key, Value: stripSources(item.Value, fmt.Sprintf("%s.%v", p, item.Key), sources)})
+		}
+
+		return r
+
+	case []any:
+		r := make([]any, 0, len(v))
+		for k, item := range v {
+			r = append(r, stripSources(item, fmt.Sprintf("%s[%d]", p, k), sources))
+		}
+
+		return r
+
+	default:
+		return value
+	}
+}
+
+// ShowConfig returns the config file with its includes merged into it, where every value is annotated with the
+// file that it is from. The values are shown as they are written in the files, therefore the references to
+// environment variables and files are not resolved, see [interpolate].
+func ShowConfig(configPath string) (string, error) {
+	content, sources, err := LoadConfigFile(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	comments := make(yaml.CommentMap, len(sources))
+	for p, source := range sources {
+		comments[p] = []*yaml.Comment{yaml.LineComment(" " + source)}
+	}
+
+	var doc yaml.MapSlice
+	if err = yaml.UnmarshalWithOptions(content, &doc, yaml.UseOrderedMap()); err != nil {
+		return "", err
+	}
+
+	b, err := yaml.MarshalWithOptions(doc, yaml.IndentSequence(true), yaml.WithComment(comments))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
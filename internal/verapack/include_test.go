@@ -0,0 +1,185 @@
+package verapack
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"team/defaults.yaml": `default:
+  verbose: true
+  sandbox_name: Team
+  hooks:
+    pre_package: npm ci
+applications:
+  - app_name: Payments API
+    package_source: https://git.example.com/payments.git
+    type: repo
+    include_modules: ["*.war"]
+  - app_name: Web
+    package_source: https://git.example.com/web.git
+`,
+		"team/apps.yaml": `include:
+  - path: defaults.yaml
+applications:
+  - app_name: Billing
+    package_source: https://git.example.com/billing.git
+`,
+		"config.yaml": `include:
+  - path: team/apps.yaml
+default:
+  hooks:
+    post_scan: notify
+applications:
+  - app_name: payments api
+    include_modules: ["api.war"]
+  - app_name: Local
+    package_source: C:\src
+`,
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, sources, err := LoadConfigFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	c, err := SetDefaults(content)
+	if err != nil {
+		t.Fatalf("SetDefaults() error = %v\n%s", err, content)
+	}
+
+	var names []string
+	for _, app := range c.Applications {
+		names = append(names, app.AppName)
+	}
+
+	if got := strings.Join(names, ", "); got != "Payments API, Web, Billing, Local" {
+		t.Errorf("applications = %s, want Payments API, Web, Billing, Local", got)
+	}
+
+	payments := c.Applications[0]
+	if payments.PackageSource != "https://git.example.com/payments.git" || strings.Join(payments.IncludeModules, ",") != "api.war" {
+		t.Errorf("payments = %s %v, want the included package_source and the overridden include_modules", payments.PackageSource, payments.IncludeModules)
+	}
+
+	if payments.Hooks.PrePackage != "npm ci" || payments.Hooks.PostScan != "notify" || payments.SandboxName != "Team" {
+		t.Errorf("payments hooks = %+v, sandbox_name = %s, want the merged defaults", payments.Hooks, payments.SandboxName)
+	}
+
+	wantSources := map[string]string{
+		"$.default.verbose":                    filepath.Join(dir, "team", "defaults.yaml"),
+		"$.default.hooks.post_scan":            filepath.Join(dir, "config.yaml"),
+		"$.applications[0].package_source":     filepath.Join(dir, "team", "defaults.yaml"),
+		"$.applications[0].include_modules":    filepath.Join(dir, "config.yaml"),
+		"$.applications[2].app_name":           filepath.Join(dir, "team", "apps.yaml"),
+		"$.applications[3].package_source":     filepath.Join(dir, "config.yaml"),
+		"$.applications[0].include_modules[0]": "",
+	}
+
+	for p, want := range wantSources {
+		if got := sources[p]; got != want {
+			t.Errorf("sources[%s] = %q, want %q", p, got, want)
+		}
+	}
+
+	show, err := ShowConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("ShowConfig() error = %v", err)
+	}
+
+	if want := "verbose: true # " + filepath.Join(dir, "team", "defaults.yaml"); !strings.Contains(show, want) {
+		t.Errorf("ShowConfig() does not contain %q:\n%s", want, show)
+	}
+}
+
+func TestLoadConfigFileCycle(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("include:\n  - path: b.yaml\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include:\n  - path: a.yaml\n"), 0600)
+
+	if _, _, err := LoadConfigFile(filepath.Join(dir, "a.yaml")); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("LoadConfigFile() error = %v, want an include cycle", err)
+	}
+}
+
+func TestLoadConfigFileMainFileOnlyKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("include:\n  - path: shared.yaml\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "shared.yaml"), []byte("mirrors:\n  wrapper: https://nexus.example.com/maven\n"), 0600)
+
+	if _, _, err := LoadConfigFile(filepath.Join(dir, "config.yaml")); err == nil || !strings.Contains(err.Error(), "'mirrors'") {
+		t.Errorf("LoadConfigFile() error = %v, want an error for mirrors in an included file", err)
+	}
+}
+
+func TestLoadConfigFileRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	os.MkdirAll(filepath.Join(repo, "verapack"), 0700)
+	os.WriteFile(filepath.Join(repo, "verapack", "shared.yaml"), []byte("applications:\n  - app_name: Shared\n    package_source: https://git.example.com/shared.git\n"), 0600)
+
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "shared"}, {"tag", "v1"}} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("include:\n  - repo: "+repo+"\n    path: verapack/shared.yaml\n    ref: v1\napplications:\n  - app_name: Local\n    package_source: C:\\src\n"), 0600)
+
+	content, sources, err := LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	names, _ := configuredApplicationNames(content)
+	if got := strings.Join(names, ", "); got != "Shared, Local" {
+		t.Errorf("applications = %s, want Shared, Local", got)
+	}
+
+	if want := repo + "@v1:verapack/shared.yaml"; sources["$.applications[0].app_name"] != want {
+		t.Errorf("source = %q, want %q", sources["$.applications[0].app_name"], want)
+	}
+
+	for _, ref := range []string{"main", ""} {
+		os.WriteFile(configPath, []byte("include:\n  - repo: "+repo+"\n    path: verapack/shared.yaml\n    ref: '"+ref+"'\n"), 0600)
+
+		if _, _, err = LoadConfigFile(configPath); err == nil {
+			t.Errorf("LoadConfigFile() with ref %q expected an error, because the include is not pinned", ref)
+		}
+	}
+}
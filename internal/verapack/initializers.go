@@ -2,9 +2,8 @@ package verapack
 
 import (
 	"fmt"
-	"net/http"
-	"net/http/cookiejar"
 	"path/filepath"
+	"slices"
 
 	"github.com/DanCreative/veracode-go/veracode"
 	"github.com/DanCreative/verapack/internal/components/multistagesetup"
@@ -13,35 +12,30 @@ import (
 )
 
 const (
-	columnPackage string = "Package"
-	columnUpload  string = "Upload"
-	columnCleanup string = "Cleanup"
-	columnResult  string = "Result"
-	columnPolicy  string = "Policy"
-	columnPromote string = "Promote"
+	columnClone       string = "Clone"
+	columnPrePackage  string = "Pre-Package"
+	columnPackage     string = "Package"
+	columnPostPackage string = "Post-Package"
+	columnInspect     string = "Inspect"
+	columnPreUpload   string = "Pre-Upload"
+	columnUpload      string = "Upload"
+	columnCleanup     string = "Cleanup"
+	columnResult      string = "Result"
+	columnPolicy      string = "Policy"
+	columnPromote     string = "Promote"
+	columnPostScan    string = "Post-Scan"
+	columnOnFailure   string = "On-Failure"
 )
 
+// NewVeracodeClient creates a client with the credentials of the profile selected by the VERACODE_API_PROFILE
+// environment variable, or the default profile, from the selected credentials store.
 func NewVeracodeClient() (*veracode.Client, error) {
-	key, secret, err := veracode.LoadVeracodeCredentials()
+	key, secret, err := loadProfileCredentials("")
 	if err != nil {
 		return nil, err
 	}
 
-	jar, err := cookiejar.New(&cookiejar.Options{})
-	if err != nil {
-		return nil, err
-	}
-
-	httpClient := &http.Client{
-		Jar: jar,
-	}
-
-	client, err := veracode.NewClient(httpClient, key, secret)
-	if err != nil {
-		return nil, err
-	}
-
-	return client, nil
+	return newVeracodeClient(key, secret)
 }
 
 func PrepareReportCard(c Config) reportcard.Model {
@@ -61,6 +55,7 @@ func PrepareReportCard(c Config) reportcard.Model {
 		reportcard.WithData(rowOptions...),
 		reportcard.WithTasks(columnOptions),
 		reportcard.WithPrefixColumns([]reportcard.Column{{Name: "Scan Type", Width: 9}}),
+		reportcard.WithViewport(viewportPrescan, &prescanViewport{}),
 	)
 }
 
@@ -109,6 +104,32 @@ func PrepareUpdate(tasks []multistagesetup.SetupTask) multistagesetup.Model {
 	)
 }
 
+func PrepareDoctor(tasks []multistagesetup.SetupTask) multistagesetup.Model {
+	return multistagesetup.NewModel(
+		multistagesetup.WithHelp(defaultHelp),
+		multistagesetup.WithSpinner(defaultSpinnerOpts...),
+		multistagesetup.WithStyles(multistagesetup.Styles{
+			StatusFailure:    multistagesetup.SummaryStyle{Symbol: '✗', Colour: red},
+			StatusSuccess:    multistagesetup.SummaryStyle{Symbol: '✓', Colour: green},
+			StatusWarning:    multistagesetup.SummaryStyle{Symbol: '⚠', Colour: orange},
+			StatusSkipped:    multistagesetup.SummaryStyle{Symbol: '-', Colour: darkGray, Style: darkGrayForeground},
+			StatusTodo:       multistagesetup.SummaryStyle{Symbol: '!'},
+			StatusInProgress: lipgloss.NewStyle().Foreground(lightBlue),
+			StageBlock: lipgloss.NewStyle().Padding(0, 1).Margin(0, 0, 0, 2).
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(darkGray),
+			MsgText: darkGrayForeground,
+			FinalMessage: lipgloss.NewStyle().Padding(0, 1, 1, 1).Margin(0, 0, 0, 2).
+				Align(lipgloss.Center).
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lightBlue),
+		}),
+		multistagesetup.WithTasks(tasks...),
+		multistagesetup.WithContinueOnFailure(),
+		multistagesetup.WithFinalMessage("Diagnostics completed without any failures."),
+	)
+}
+
 func hasPromoteTask(c Options) bool {
 	return c.ScanType == ScanTypePromote || (c.ScanType == ScanTypeSandbox && c.AutoPromote)
 }
@@ -129,29 +150,89 @@ func hasUploadTask(c Options) bool {
 	return c.ScanType == ScanTypePolicy || c.ScanType == ScanTypeSandbox
 }
 
+func hasInspectTask(c Options) bool {
+	return hasUploadTask(c)
+}
+
 func hasPolicyTask(c Options) bool {
 	return (c.ScanType == ScanTypePolicy && c.WaitForResult) || (c.ScanType == ScanTypeSandbox && c.AutoPromote)
 }
 
+func hasPrePackageHookTask(c Options) bool {
+	return hasPackageTask(c) && c.Hooks.PrePackage != ""
+}
+
+func hasPostPackageHookTask(c Options) bool {
+	return hasPackageTask(c) && c.Hooks.PostPackage != ""
+}
+
+func hasPreUploadHookTask(c Options) bool {
+	return hasUploadTask(c) && c.Hooks.PreUpload != ""
+}
+
+func hasPostScanHookTask(c Options) bool {
+	return hasUploadTask(c) && c.Hooks.PostScan != ""
+}
+
+func hasOnFailureHookTask(c Options) bool {
+	return hasUploadTask(c) && c.Hooks.OnFailure != ""
+}
+
 func getColumns(c Config) []reportcard.Column {
-	columnsOption := make([]reportcard.Column, 0, 6)
-	var columnPromoteAdd, columnPackageAdd, columnCleanupAdd, columnResultAdd, columnUploadAdd, columnPolicyAdd bool
+	columnsOption := make([]reportcard.Column, 0, 13)
+	var columnPromoteAdd, columnPackageAdd, columnCleanupAdd, columnResultAdd, columnUploadAdd, columnPolicyAdd, columnInspectAdd bool
+	var columnPrePackageAdd, columnPostPackageAdd, columnPreUploadAdd, columnPostScanAdd, columnOnFailureAdd bool
+
+	// The shared clone step is only shown if there are applications that share a clone.
+	columnCloneAdd := len(groupSharedClones(c.Applications)) > 0
 
 	for _, app := range c.Applications {
 		columnPackageAdd = columnPackageAdd || hasPackageTask(app)
 		columnUploadAdd = columnUploadAdd || hasUploadTask(app)
+		columnInspectAdd = columnInspectAdd || hasInspectTask(app)
 		columnCleanupAdd = columnCleanupAdd || hasCleanupTask(app)
 		columnResultAdd = columnResultAdd || hasResultTask(app)
 		columnPolicyAdd = columnPolicyAdd || hasPolicyTask(app)
 		columnPromoteAdd = columnPromoteAdd || hasPromoteTask(app)
+		columnPrePackageAdd = columnPrePackageAdd || hasPrePackageHookTask(app)
+		columnPostPackageAdd = columnPostPackageAdd || hasPostPackageHookTask(app)
+		columnPreUploadAdd = columnPreUploadAdd || hasPreUploadHookTask(app)
+		columnPostScanAdd = columnPostScanAdd || hasPostScanHookTask(app)
+		columnOnFailureAdd = columnOnFailureAdd || hasOnFailureHookTask(app)
+	}
+
+	if columnCloneAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnClone, Width: 5})
+	}
+
+	if columnPrePackageAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnPrePackage, Width: 11})
 	}
 
 	if columnPackageAdd {
 		columnsOption = append(columnsOption, reportcard.Column{Name: columnPackage, Width: 7})
 	}
 
+	if columnPostPackageAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnPostPackage, Width: 12})
+	}
+
+	if columnInspectAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnInspect, Width: 7})
+	}
+
+	if columnPreUploadAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnPreUpload, Width: 10})
+	}
+
 	if columnUploadAdd {
-		columnsOption = append(columnsOption, reportcard.Column{Name: columnUpload, Width: 6})
+		// The upload column shows the "Unchanged" status of applications that are skipped.
+		width := 6
+		if slices.ContainsFunc(c.Applications, func(app Options) bool { return app.SkipIfUnchanged }) {
+			width = 9
+		}
+
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnUpload, Width: width})
 	}
 
 	if columnCleanupAdd {
@@ -170,25 +251,54 @@ func getColumns(c Config) []reportcard.Column {
 		columnsOption = append(columnsOption, reportcard.Column{Name: columnPolicy, Width: 8})
 	}
 
+	if columnPostScanAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnPostScan, Width: 9})
+	}
+
+	if columnOnFailureAdd {
+		columnsOption = append(columnsOption, reportcard.Column{Name: columnOnFailure, Width: 10})
+	}
+
 	return columnsOption
 }
 
 func getRows(c Config, columns []reportcard.Column) []reportcard.Row {
 	rowOptions := make([]reportcard.Row, 0, len(c.Applications))
+	shared := groupSharedClones(c.Applications)
 
-	for _, app := range c.Applications {
-		tasks := make([]reportcard.Task, 0, 6)
+	for k, app := range c.Applications {
+		tasks := make([]reportcard.Task, 0, 13)
+
+		if shared[k] != nil {
+			tasks = append(tasks, reportcard.NewTask(columnClone))
+		}
+
+		if hasPrePackageHookTask(app) {
+			tasks = append(tasks, reportcard.NewTask(columnPrePackage))
+		}
 
 		if hasPackageTask(app) {
 			tasks = append(tasks, reportcard.NewTask(columnPackage))
 		}
 
+		if hasPostPackageHookTask(app) {
+			tasks = append(tasks, reportcard.NewTask(columnPostPackage))
+		}
+
+		if hasInspectTask(app) {
+			tasks = append(tasks, reportcard.NewTask(columnInspect))
+		}
+
+		if hasPreUploadHookTask(app) {
+			tasks = append(tasks, reportcard.NewTask(columnPreUpload))
+		}
+
 		if hasUploadTask(app) {
 			tasks = append(tasks, reportcard.NewTask(columnUpload))
 		}
 
 		if hasCleanupTask(app) {
-			tasks = append(tasks, reportcard.NewTask(columnCleanup, columnPackage, columnUpload))
+			tasks = append(tasks, reportcard.NewTask(columnCleanup, columnPrePackage, columnPackage, columnPostPackage, columnInspect, columnPreUpload, columnUpload))
 		}
 
 		if hasResultTask(app) {
@@ -203,6 +313,18 @@ func getRows(c Config, columns []reportcard.Column) []reportcard.Row {
 			tasks = append(tasks, reportcard.NewTask(columnPromote))
 		}
 
+		if hasPostScanHookTask(app) {
+			tasks = append(tasks, reportcard.NewTask(columnPostScan))
+		}
+
+		if hasOnFailureHookTask(app) {
+			// The on_failure hook runs after any of the other tasks failed.
+			tasks = append(tasks, reportcard.NewTask(columnOnFailure,
+				columnClone, columnPrePackage, columnPackage, columnPostPackage, columnInspect, columnPreUpload, columnUpload,
+				columnCleanup, columnResult, columnPromote, columnPolicy, columnPostScan,
+			))
+		}
+
 		rowOptions = append(rowOptions, reportcard.NewRow(app.AppName, tasks, []string{string(app.ScanType)}, columns))
 	}
 
@@ -0,0 +1,339 @@
+package verapack
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/pe"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DanCreative/verapack/internal/components/reportcard"
+)
+
+// maxArchiveSize is the maximum size of a single file that can be uploaded to the Veracode platform.
+const maxArchiveSize = 2 << 30
+
+var (
+	// archiveExtensions are the file types that are reported if they are larger than [maxArchiveSize].
+	archiveExtensions = []string{".zip", ".jar", ".war", ".ear", ".apk", ".aab", ".ipa", ".tar", ".tgz", ".gz"}
+
+	// zipExtensions are the zip archives whose entries are inspected, like the files of an artefact directory.
+	zipExtensions = []string{".zip", ".jar", ".war", ".ear"}
+
+	// scannableExtensions are the file types that can be analysed by the Veracode platform. The list is not
+	// exhaustive, it is only used to warn about uploads that most likely do not contain anything to scan.
+	scannableExtensions = append([]string{
+		".class", ".dll", ".exe", ".aar", ".xcarchive",
+		".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".vue", ".html",
+		".py", ".php", ".rb", ".go", ".kt", ".scala", ".swift", ".cls", ".trigger", ".pl",
+	}, archiveExtensions...)
+)
+
+// artefactFilter returns whether the file at the relative path rel, inside one of the artefacts, is uploaded.
+// If include is set, the file must match one of its globs. The file must not match any of the exclude globs.
+func artefactFilter(include, exclude []string, rel string) bool {
+	if len(include) > 0 && !matchAnyGlob(include, rel) {
+		return false
+	}
+
+	return !matchAnyGlob(exclude, rel)
+}
+
+// filterArtefacts copies the files of the artefacts that pass [artefactFilter] into stageDir, and returns the
+// new artefact paths. The globs are matched against the paths of the files relative to the artefact directory,
+// or against the file name if the artefact is a file. The structure of the artefacts is kept, so that the
+// filtered artefacts are uploaded in the same way as the original artefacts. The artefacts are staged under their
+// base names, therefore artefacts with the same base name are an error.
+func filterArtefacts(artefactPaths, include, exclude []string, stageDir string) ([]string, error) {
+	staged := make(map[string]string, len(artefactPaths))
+
+	for _, artefactPath := range artefactPaths {
+		base := strings.ToLower(filepath.Base(artefactPath))
+		if other, ok := staged[base]; ok {
+			return nil, fmt.Errorf("artefacts %s and %s have the same name, rename one of them to use include or exclude", other, artefactPath)
+		}
+		staged[base] = artefactPath
+
+		info, err := os.Stat(artefactPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			if artefactFilter(include, exclude, filepath.Base(artefactPath)) {
+				if err = copyFile(artefactPath, filepath.Join(stageDir, filepath.Base(artefactPath))); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(artefactPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(artefactPath, p)
+			if err != nil {
+				return err
+			}
+
+			if !artefactFilter(include, exclude, rel) {
+				return nil
+			}
+
+			return copyFile(p, filepath.Join(stageDir, filepath.Base(artefactPath), rel))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths, err := getArtefactPath(stageDir)
+	if err != nil || len(paths) == 0 {
+		return nil, fmt.Errorf("%w: none of the files match the include and exclude filters", errNoArtifacts)
+	}
+
+	return paths, nil
+}
+
+// artefactReport contains the result of inspecting the artefacts before they are uploaded.
+type artefactReport struct {
+	files    int
+	size     int64
+	types    map[string]int // Number of files per extension.
+	warnings []string
+}
+
+// String renders the totals, the number of files per type, and the warnings of the report.
+func (r artefactReport) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Total: %d files, %s\n", r.files, formatBytes(r.size))
+
+	types := make([]string, 0, len(r.types))
+	for ext := range r.types {
+		types = append(types, ext)
+	}
+	slices.Sort(types)
+
+	for _, ext := range types {
+		fmt.Fprintf(&b, "  %s: %d\n", ext, r.types[ext])
+	}
+
+	for _, warning := range r.warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", warning)
+	}
+
+	return b.String()
+}
+
+// inspectArtefacts walks the artefacts and reports their size and file types, along with warnings about likely
+// mistakes: .NET assemblies without debug symbols, only minified JavaScript, archives that are larger than
+// [maxArchiveSize], and uploads without any scannable files. The entries of zip archives, e.g. .jar and .war
+// files, are checked for .NET assemblies and minified JavaScript as well. Archives inside archives are not opened.
+func inspectArtefacts(artefactPaths []string) (artefactReport, error) {
+	r := artefactReport{types: make(map[string]int)}
+
+	var assemblies []string
+	pdbs := make(map[string]bool)
+	var js, minifiedJs, scannable int
+
+	// check checks a file of an artefact, or an entry of an archive, at p. isAssembly is only called for .dll
+	// and .exe files.
+	check := func(p string, isAssembly func() bool) {
+		name := strings.ToLower(filepath.Base(p))
+
+		switch filepath.Ext(name) {
+		case ".pdb":
+			pdbs[strings.ToLower(strings.TrimSuffix(p, filepath.Ext(p)))] = true
+		case ".dll", ".exe":
+			if isAssembly() {
+				assemblies = append(assemblies, p)
+			}
+		case ".js":
+			js++
+			if strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, "-min.js") {
+				minifiedJs++
+			}
+		}
+	}
+
+	for _, artefactPath := range artefactPaths {
+		err := filepath.WalkDir(artefactPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			ext := filepath.Ext(strings.ToLower(d.Name()))
+
+			r.files++
+			r.size += info.Size()
+
+			if ext == "" {
+				r.types["(none)"]++
+			} else {
+				r.types[ext]++
+			}
+
+			if slices.Contains(scannableExtensions, ext) {
+				scannable++
+			}
+
+			if slices.Contains(archiveExtensions, ext) && info.Size() > maxArchiveSize {
+				r.warnings = append(r.warnings, fmt.Sprintf("%s is %s, which is larger than the upload limit of %s", p, formatBytes(info.Size()), formatBytes(maxArchiveSize)))
+			}
+
+			if slices.Contains(zipExtensions, ext) {
+				if err = inspectZip(p, check); err != nil {
+					r.warnings = append(r.warnings, fmt.Sprintf("the archive %s could not be inspected: %s", p, err))
+				}
+				return nil
+			}
+
+			check(p, func() bool { return isDotNetAssembly(p) })
+
+			return nil
+		})
+		if err != nil {
+			return r, err
+		}
+	}
+
+	for _, assembly := range assemblies {
+		if !pdbs[strings.ToLower(strings.TrimSuffix(assembly, filepath.Ext(assembly)))] {
+			r.warnings = append(r.warnings, fmt.Sprintf("the .NET assembly %s does not have a PDB file, which is required to report the source locations of the findings", assembly))
+		}
+	}
+
+	if js > 0 && js == minifiedJs {
+		r.warnings = append(r.warnings, "all of the JavaScript files are minified, upload the unminified source instead")
+	}
+
+	if scannable == 0 {
+		r.warnings = append(r.warnings, "none of the files can be scanned, check that the artefacts contain the compiled application or its source")
+	}
+
+	return r, nil
+}
+
+// inspectZip calls check for the files in the zip archive at path. The paths of the files are joined to path,
+// e.g. app.zip/bin/App.dll.
+func inspectZip(path string, check func(p string, isAssembly func() bool)) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		check(filepath.Join(path, filepath.FromSlash(f.Name)), func() bool {
+			rc, err := f.Open()
+			if err != nil {
+				return false
+			}
+			defer rc.Close()
+
+			// The PE headers are read at random offsets, therefore the entry is read into memory.
+			content, err := io.ReadAll(rc)
+			if err != nil {
+				return false
+			}
+
+			return isDotNetAssemblyReader(bytes.NewReader(content))
+		})
+	}
+
+	return nil
+}
+
+// isDotNetAssembly returns whether the file is a PE file with a CLR header, i.e. a .NET assembly.
+func isDotNetAssembly(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	return isDotNetAssemblyReader(f)
+}
+
+// isDotNetAssemblyReader returns whether r contains a PE file with a CLR header, see [isDotNetAssembly].
+func isDotNetAssemblyReader(r io.ReaderAt) bool {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return false
+	}
+
+	var dirs []pe.DataDirectory
+	switch h := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = h.DataDirectory[:h.NumberOfRvaAndSizes]
+	case *pe.OptionalHeader64:
+		dirs = h.DataDirectory[:h.NumberOfRvaAndSizes]
+	}
+
+	return len(dirs) > pe.IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR && dirs[pe.IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR].VirtualAddress != 0
+}
+
+// inspectTask applies the include and exclude filters of the application to its artefacts, and reports the
+// inspection of the artefacts that are uploaded. The filtered artefacts are copied into stageDir. It returns
+// the artefact paths that should be uploaded.
+func inspectTask(options Options, stageDir string, appId int, reporter reporter, writer io.Writer) ([]string, error) {
+	fmt.Fprintf(writer, "BEGIN (%s)\n", columnInspect)
+	defer fmt.Fprintf(writer, "END (%s)\n", columnInspect)
+
+	artefactPaths := options.ArtefactPaths
+
+	fail := func(err error) ([]string, error) {
+		fmt.Fprintln(writer, err)
+		reporter.Send(reportcard.TaskResultMsg{
+			Status: reportcard.Failure,
+			Output: err.Error(),
+			Index:  appId,
+		})
+		return nil, err
+	}
+
+	if len(options.Include) > 0 || len(options.Exclude) > 0 {
+		var err error
+		if artefactPaths, err = filterArtefacts(artefactPaths, options.Include, options.Exclude, stageDir); err != nil {
+			return fail(err)
+		}
+	}
+
+	report, err := inspectArtefacts(artefactPaths)
+	if err != nil {
+		return fail(err)
+	}
+
+	out := report.String()
+	fmt.Fprint(writer, out)
+
+	status := reportcard.Success
+	if len(report.warnings) > 0 {
+		status = reportcard.Warning
+	}
+
+	reporter.Send(reportcard.TaskResultMsg{
+		Status: status,
+		Output: out,
+		Index:  appId,
+	})
+
+	return artefactPaths, nil
+}
@@ -0,0 +1,196 @@
+package verapack
+
+import (
+	"archive/zip"
+	"debug/pe"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestArtefacts creates a directory with the files, which are slash-separated paths.
+func newTestArtefacts(t *testing.T, files ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, file := range files {
+		p := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestFilterArtefacts(t *testing.T) {
+	dir := newTestArtefacts(t, "app.jar", "lib/dep.jar", "lib/dep-tests.jar", "node_modules/x/index.js", "README.md")
+	stage := filepath.Join(t.TempDir(), "filtered")
+
+	paths, err := filterArtefacts([]string{dir}, []string{"**/*.jar", "**/*.js"}, []string{"**/*-tests.jar", "node_modules/**"}, stage)
+	if err != nil {
+		t.Fatalf("filterArtefacts() error = %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != filepath.Join(stage, filepath.Base(dir)) {
+		t.Fatalf("filterArtefacts() = %v, want the staged directory", paths)
+	}
+
+	report, err := inspectArtefacts(paths)
+	if err != nil {
+		t.Fatalf("inspectArtefacts() error = %v", err)
+	}
+
+	if report.files != 2 || report.types[".jar"] != 2 {
+		t.Errorf("filterArtefacts() staged %d files: %v, want app.jar and lib/dep.jar", report.files, report.types)
+	}
+
+	if _, err = filterArtefacts([]string{dir}, []string{"*.war"}, nil, filepath.Join(t.TempDir(), "filtered")); err == nil {
+		t.Errorf("filterArtefacts() expected an error if none of the files match")
+	}
+
+	other := newTestArtefacts(t, "bin/other.jar")
+	if _, err = filterArtefacts([]string{filepath.Join(dir, "lib"), filepath.Join(other, "bin"), filepath.Join(newTestArtefacts(t, "lib/x.jar"), "lib")}, nil, nil, filepath.Join(t.TempDir(), "filtered")); err == nil || !strings.Contains(err.Error(), "same name") {
+		t.Errorf("filterArtefacts() error = %v, want an error for artefacts with the same name", err)
+	}
+}
+
+func TestInspectArtefacts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "scannable", files: []string{"classes/App.class", "js/app.js"}},
+		{name: "invalid archive", files: []string{"app.war"}, want: "the archive"},
+		{name: "minified", files: []string{"js/app.min.js", "js/vendor-min.js"}, want: "minified"},
+		{name: "not scannable", files: []string{"README.md", "logo.png"}, want: "none of the files can be scanned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := inspectArtefacts([]string{newTestArtefacts(t, tt.files...)})
+			if err != nil {
+				t.Fatalf("inspectArtefacts() error = %v", err)
+			}
+
+			if tt.want == "" {
+				if len(report.warnings) > 0 {
+					t.Errorf("inspectArtefacts() warnings = %v, want none", report.warnings)
+				}
+				return
+			}
+
+			if !strings.Contains(strings.Join(report.warnings, "\n"), tt.want) {
+				t.Errorf("inspectArtefacts() warnings = %v, want %q", report.warnings, tt.want)
+			}
+		})
+	}
+}
+
+// newTestAssembly writes a minimal PE file with a CLR header, which [isDotNetAssembly] reports as a .NET assembly.
+func newTestAssembly(t *testing.T, path string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, testAssembly(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAssembly returns the content of the test assembly, see [newTestAssembly].
+func testAssembly() []byte {
+	b := make([]byte, 0x40+4+20+224)
+	copy(b, "MZ")
+	binary.LittleEndian.PutUint32(b[0x3c:], 0x40)
+	copy(b[0x40:], "PE\x00\x00")
+
+	fileHeader := b[0x44:]
+	binary.LittleEndian.PutUint16(fileHeader[0:], pe.IMAGE_FILE_MACHINE_I386)
+	binary.LittleEndian.PutUint16(fileHeader[16:], 224)
+
+	optionalHeader := b[0x44+20:]
+	binary.LittleEndian.PutUint16(optionalHeader[0:], 0x10b)
+	binary.LittleEndian.PutUint32(optionalHeader[92:], 16)
+	binary.LittleEndian.PutUint32(optionalHeader[96+pe.IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR*8:], 0x2000)
+
+	return b
+}
+
+// newTestZip writes a zip archive with the files, by their paths in the archive.
+func newTestZip(t *testing.T, path string, files map[string][]byte) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = entry.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInspectArtefactsPdb(t *testing.T) {
+	dir := newTestArtefacts(t, "Bin/Release/App.pdb")
+	newTestAssembly(t, filepath.Join(dir, "Bin", "Release", "App.dll"))
+	newTestAssembly(t, filepath.Join(dir, "Bin", "Release", "Lib.dll"))
+
+	if !isDotNetAssembly(filepath.Join(dir, "Bin", "Release", "App.dll")) {
+		t.Fatal("isDotNetAssembly() = false, want the test assembly to be a .NET assembly")
+	}
+
+	report, err := inspectArtefacts([]string{dir})
+	if err != nil {
+		t.Fatalf("inspectArtefacts() error = %v", err)
+	}
+
+	warnings := strings.Join(report.warnings, "\n")
+	if strings.Contains(warnings, "App.dll") || !strings.Contains(warnings, "Lib.dll does not have a PDB file") {
+		t.Errorf("inspectArtefacts() warnings = %v, want only Lib.dll without a PDB file", report.warnings)
+	}
+}
+
+func TestInspectArtefactsArchive(t *testing.T) {
+	dir := t.TempDir()
+	newTestZip(t, filepath.Join(dir, "App.zip"), map[string][]byte{
+		"bin/App.dll": testAssembly(),
+		"bin/App.pdb": []byte("pdb"),
+		"bin/Lib.dll": testAssembly(),
+	})
+	newTestZip(t, filepath.Join(dir, "web.war"), map[string][]byte{"js/app.min.js": []byte("js")})
+
+	report, err := inspectArtefacts([]string{dir})
+	if err != nil {
+		t.Fatalf("inspectArtefacts() error = %v", err)
+	}
+
+	if report.files != 2 {
+		t.Errorf("inspectArtefacts() files = %d, want the archives to be counted as one file each", report.files)
+	}
+
+	warnings := strings.Join(report.warnings, "\n")
+	if strings.Contains(warnings, "App.dll") || !strings.Contains(warnings, filepath.Join("App.zip", "bin", "Lib.dll")+" does not have a PDB file") {
+		t.Errorf("inspectArtefacts() warnings = %v, want only Lib.dll in the archive without a PDB file", report.warnings)
+	}
+
+	if !strings.Contains(warnings, "minified") {
+		t.Errorf("inspectArtefacts() warnings = %v, want the minified JavaScript in the archive", report.warnings)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"net/http/cookiejar"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,18 +15,20 @@ import (
 // InstallUploader installs the uploader jar file to the latest version.
 //
 // It automatically updates the existing install to the latest version.
-func InstallUploader(dirPath string) (string, error) {
+//
+// The jar is downloaded from the mirror if its URL is set, otherwise from Maven Central.
+func InstallUploader(dirPath string, mirror Mirror) (string, error) {
 	jar, _ := cookiejar.New(&cookiejar.Options{})
 	client := &http.Client{
 		Jar: jar,
 	}
 
-	version, err := GetLatestUploaderVersion(client)
+	version, err := GetLatestUploaderVersion(client, mirror)
 	if err != nil {
 		return "", err
 	}
 
-	downloadPath, err := downloadUploaderArchive(client, version)
+	downloadPath, err := downloadUploaderArchive(client, mirror, version)
 	if err != nil {
 		return "", err
 	}
@@ -56,18 +57,22 @@ func InstallUploader(dirPath string) (string, error) {
 }
 
 // GetLatestUploaderVersion returns the latest version of the Veracode API wrapper jar.
-func GetLatestUploaderVersion(client *http.Client) (string, error) {
-	req, err := http.NewRequest(http.MethodGet, "https://repo1.maven.org/maven2/com/veracode/vosp/api/wrappers/vosp-api-wrappers-java/maven-metadata.xml", nil)
+func GetLatestUploaderVersion(client *http.Client, mirror Mirror) (string, error) {
+	baseURL, err := mirror.baseURL(defaultWrapperBaseURL)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := client.Do(req)
+	resp, err := mirror.get(client, baseURL.JoinPath(wrapperArtifactPath, "maven-metadata.xml").String())
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if err = checkDownloadStatus(resp); err != nil {
+		return "", err
+	}
+
 	bytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -93,7 +98,12 @@ func GetLatestUploaderVersion(client *http.Client) (string, error) {
 
 // downloadUploaderArchive streams the archive for the provided version from the remote source to
 // a temporarily local file.
-func downloadUploaderArchive(client *http.Client, version string) (string, error) {
+func downloadUploaderArchive(client *http.Client, mirror Mirror, version string) (string, error) {
+	baseURL, err := mirror.baseURL(defaultWrapperBaseURL)
+	if err != nil {
+		return "", err
+	}
+
 	file, err := os.CreateTemp("", "wrapper_*.zip")
 	if err != nil {
 		return "", err
@@ -101,13 +111,17 @@ func downloadUploaderArchive(client *http.Client, version string) (string, error
 
 	defer file.Close()
 
-	resp, err := client.Get(fmt.Sprintf("https://repo1.maven.org/maven2/com/veracode/vosp/api/wrappers/vosp-api-wrappers-java/%s/vosp-api-wrappers-java-%s-dist.zip", version, version))
+	resp, err := mirror.get(client, baseURL.JoinPath(wrapperArtifactPath, version, fmt.Sprintf("vosp-api-wrappers-java-%s-dist.zip", version)).String())
 	if err != nil {
 		return "", err
 	}
 
 	defer resp.Body.Close()
 
+	if err = checkDownloadStatus(resp); err != nil {
+		return "", err
+	}
+
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
 		return "", err
@@ -125,11 +139,11 @@ func downloadUploaderArchive(client *http.Client, version string) (string, error
 //
 // NOTE: This function is OS/ARCH agnostic, but the functions it calls are not. In order to build this
 // application for different environment, implement the required functions for that environment/tech.
-func InstallPackager(shouldFullyInstall bool, dirPath string) (string, error) {
+func InstallPackager(shouldFullyInstall bool, dirPath string, mirror Mirror) (string, error) {
 	if shouldFullyInstall {
 		return fullPackagerInstall()
 	} else {
-		return partialPackagerInstall(dirPath)
+		return partialPackagerInstall(dirPath, mirror)
 	}
 }
 
@@ -139,22 +153,23 @@ func InstallPackager(shouldFullyInstall bool, dirPath string) (string, error) {
 //
 // On windows the directory is: %AppData%\veracode
 //
+// The archive is downloaded from the mirror if its URL is set, otherwise from tools.veracode.com.
+//
 // NOTE: This function is OS/ARCH agnostic, but the functions it calls are not. In order to build this
 // application for different environment, implement the required functions for that environment/tech.
-func partialPackagerInstall(dirPath string) (string, error) {
+func partialPackagerInstall(dirPath string, mirror Mirror) (string, error) {
 	jar, _ := cookiejar.New(&cookiejar.Options{})
 	client := &http.Client{
 		Jar: jar,
 	}
-	baseURL, _ := url.Parse("https://tools.veracode.com/veracode-cli")
 
-	fileVersion, err := GetLatestPackagerVersion(client, baseURL)
+	fileVersion, err := GetLatestPackagerVersion(client, mirror)
 	if err != nil {
 		return "", err
 	}
 	fileName, ext := getPackagerFileName(fileVersion)
 
-	downloadedPath, err := downloadPackagerArchive(client, baseURL, ext, fileName)
+	downloadedPath, err := downloadPackagerArchive(client, mirror, ext, fileName)
 	if err != nil {
 		return "", err
 	}
@@ -172,18 +187,22 @@ func partialPackagerInstall(dirPath string) (string, error) {
 // GetLatestPackagerVersion gets the latest version of the packager.
 //
 // Typical Dev comment;)
-func GetLatestPackagerVersion(client *http.Client, baseURL *url.URL) (string, error) {
-	req, err := http.NewRequest(http.MethodGet, baseURL.JoinPath("/LATEST_VERSION").String(), nil)
+func GetLatestPackagerVersion(client *http.Client, mirror Mirror) (string, error) {
+	baseURL, err := mirror.baseURL(defaultPackagerBaseURL)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := client.Do(req)
+	resp, err := mirror.get(client, baseURL.JoinPath("/LATEST_VERSION").String())
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	if err = checkDownloadStatus(resp); err != nil {
+		return "", err
+	}
+
 	bytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
@@ -194,7 +213,12 @@ func GetLatestPackagerVersion(client *http.Client, baseURL *url.URL) (string, er
 
 // downloadPackagerArchive streams the archive from the remote source to
 // a temporarily local file.
-func downloadPackagerArchive(client *http.Client, baseURL *url.URL, extension, fileName string) (string, error) {
+func downloadPackagerArchive(client *http.Client, mirror Mirror, extension, fileName string) (string, error) {
+	baseURL, err := mirror.baseURL(defaultPackagerBaseURL)
+	if err != nil {
+		return "", err
+	}
+
 	file, err := os.CreateTemp("", "veracode_*."+extension)
 	if err != nil {
 		return "", err
@@ -202,13 +226,17 @@ func downloadPackagerArchive(client *http.Client, baseURL *url.URL, extension, f
 
 	defer file.Close()
 
-	resp, err := client.Get(baseURL.JoinPath(fileName).String())
+	resp, err := mirror.get(client, baseURL.JoinPath(fileName).String())
 	if err != nil {
 		return "", err
 	}
 
 	defer resp.Body.Close()
 
+	if err = checkDownloadStatus(resp); err != nil {
+		return "", err
+	}
+
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
 		return "", err
@@ -235,6 +263,16 @@ func InstallScaAgent(packagerPath string) error {
 	return nil
 }
 
+// checkDownloadStatus returns an error if the response from the download source was not successful.
+// Without this check, an error page from a mirror would be saved as the archive.
+func checkDownloadStatus(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("download from '%s' failed with status: %s", resp.Request.URL.Redacted(), resp.Status)
+	}
+
+	return nil
+}
+
 func GetLocalVersion(path string) string {
 	file, _ := os.ReadFile(path)
 	if len(file) < 1 {
@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"golang.org/x/sys/windows"
 )
 
 func InstallCli() error {
@@ -153,3 +155,30 @@ func getPackagerLocation() string {
 func getWrapperLocation() string {
 	return filepath.Join(os.Getenv("AppData"), "veracode", "wrapper")
 }
+
+// getScaAgentLocation gets the directory that the Veracode CLI downloads the SCA agent to
+// the first time that it packages a project.
+//
+// NOTE: This is the windows implementation.
+func getScaAgentLocation(homeDir string) string {
+	return filepath.Join(homeDir, ".veracode", "srcclr")
+}
+
+// getFreeDiskSpace returns the number of bytes that are available to the user on the volume that
+// contains path.
+//
+// NOTE: This is the windows implementation.
+func getFreeDiskSpace(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeBytesAvailable uint64
+
+	if err = windows.GetDiskFreeSpaceEx(p, &freeBytesAvailable, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return freeBytesAvailable, nil
+}
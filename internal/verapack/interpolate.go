@@ -0,0 +1,237 @@
+package verapack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// variableNameRegex matches the name of an environment variable.
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UnsetVariableError is the error of a reference to an environment variable that is not set and has no default value.
+type UnsetVariableError struct {
+	Name string
+}
+
+func (e *UnsetVariableError) Error() string {
+	return fmt.Sprintf("environment variable '%s' is not set. Set it, or add a default value with ${%s:-default}", e.Name, e.Name)
+}
+
+// interpolationError is the error of a reference in the setting at path.
+type interpolationError struct {
+	path string
+	err  error
+}
+
+func (e *interpolationError) Error() string {
+	return fmt.Sprintf("config validation error at %s: %s", e.path, e.err)
+}
+
+func (e *interpolationError) Unwrap() error {
+	return e.err
+}
+
+// interpolateConfig replaces the references in the string values of the config. The interpolated values are
+// returned by the path of the setting, e.g. config.applications[0].package_source, so that they can be redacted.
+// The errors are [interpolationError]s, so that the errors of an application can be told apart.
+func interpolateConfig(v any) (map[string][]string, []error) {
+	secrets := make(map[string][]string)
+
+	var errs []error
+	interpolateValue(reflect.ValueOf(v).Elem(), "config", secrets, &errs)
+
+	return secrets, errs
+}
+
+// applicationIndex returns the index of the application that the [interpolationError] belongs to.
+func applicationIndex(err error) (int, bool) {
+	var ie *interpolationError
+	if !errors.As(err, &ie) {
+		return 0, false
+	}
+
+	var i int
+	if _, err = fmt.Sscanf(ie.path, "config.applications[%d]", &i); err != nil {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// interpolateValue replaces the references in the strings of the value, recursively.
+func interpolateValue(v reflect.Value, path string, secrets map[string][]string, errs *[]error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), path, secrets, errs)
+		}
+
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if name, ok := yamlName(v.Type().Field(i)); ok {
+				interpolateValue(v.Field(i), path+"."+name, secrets, errs)
+			}
+		}
+
+	case reflect.Slice:
+		for k := range v.Len() {
+			interpolateValue(v.Index(k), fmt.Sprintf("%s[%d]", path, k), secrets, errs)
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			item := reflect.New(v.Type().Elem()).Elem()
+			item.Set(v.MapIndex(key))
+
+			interpolateValue(item, fmt.Sprintf("%s.%s", path, key), secrets, errs)
+			v.SetMapIndex(key, item)
+		}
+
+	case reflect.String:
+		value, interpolated, err := interpolate(v.String())
+		if err != nil {
+			*errs = append(*errs, &interpolationError{path: path, err: err})
+			return
+		}
+
+		v.SetString(value)
+
+		if len(interpolated) > 0 {
+			secrets[path] = interpolated
+		}
+	}
+}
+
+// interpolate replaces the references in the value with:
+//
+//   - ${VAR}: the value of the environment variable. It is an error if the variable is not set.
+//   - ${VAR:-default}: the value of the environment variable, or the default value if it is not set or empty.
+//   - ${file:path}: the content of the file, without trailing new lines. A leading ~ is the user's home directory.
+//
+// $${ is replaced with ${, to escape a reference. The variables of the hooks, see [hookEnv], are set when
+// the hooks are run, therefore the references to them are kept. The values of the environment variables and
+// files are returned, so that they can be redacted. The default values are not, because they are in the config.
+func interpolate(value string) (string, []string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil, nil
+	}
+
+	var b strings.Builder
+	var secrets []string
+
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			b.WriteString(value)
+			break
+		}
+
+		if start > 0 && value[start-1] == '$' {
+			// Escaped reference.
+			b.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+
+		end := strings.Index(value[start:], "}")
+		if end == -1 {
+			return "", nil, fmt.Errorf("reference '%s' is missing a closing '}'", value[start:])
+		}
+
+		end += start
+		b.WriteString(value[:start])
+
+		resolved, secret, err := resolveReference(value[start+2 : end])
+		if err != nil {
+			return "", nil, err
+		}
+
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+
+		b.WriteString(resolved)
+		value = value[end+1:]
+	}
+
+	return b.String(), secrets, nil
+}
+
+// resolveReference returns the value of the reference without ${ and }, and the value if it is a secret.
+func resolveReference(reference string) (string, string, error) {
+	if path, ok := strings.CutPrefix(reference, "file:"); ok {
+		if rest, ok := strings.CutPrefix(path, "~"); ok {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", err
+			}
+
+			path = filepath.Join(homeDir, rest)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("file '%s' of reference '${%s}' could not be read: %w", path, reference, err)
+		}
+
+		value := strings.TrimRight(string(content), "\r\n")
+		return value, value, nil
+	}
+
+	name, defaultValue, hasDefault := strings.Cut(reference, ":-")
+	if !variableNameRegex.MatchString(name) {
+		return "", "", fmt.Errorf("'${%s}' is not a valid reference, the reference must be ${VAR}, ${VAR:-default} or ${file:path}", reference)
+	}
+
+	if slices.Contains(hookVariables(), name) {
+		return "${" + reference + "}", "", nil
+	}
+
+	value, ok := os.LookupEnv(name)
+
+	switch {
+	case hasDefault && value == "":
+		return defaultValue, "", nil
+	case !ok:
+		return "", "", &UnsetVariableError{Name: name}
+	}
+
+	return value, value, nil
+}
+
+// hookVariables returns the names of the environment variables of the hooks.
+func hookVariables() []string {
+	environ := hookEnv{}.environ("")
+
+	names := make([]string, 0, len(environ))
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// secretsOf returns the interpolated values of the settings that start with one of the prefixes.
+func secretsOf(secrets map[string][]string, prefixes ...string) []string {
+	var r []string
+
+	for path, values := range secrets {
+		for _, prefix := range prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+				r = append(r, values...)
+				break
+			}
+		}
+	}
+
+	slices.Sort(r)
+
+	return slices.Compact(r)
+}
@@ -0,0 +1,111 @@
+package verapack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("VERAPACK_TEST_SOURCE", `C:\src`)
+	t.Setenv("VERAPACK_TEST_EMPTY", "")
+	t.Setenv("VERAPACK_TEST_TOKEN", "t0ken-123")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		value      string
+		want       string
+		wantSecret []string
+		wantErr    bool
+	}{
+		{name: "plain", value: "make build", want: "make build"},
+		{name: "variable", value: `${VERAPACK_TEST_SOURCE}\api`, want: `C:\src\api`, wantSecret: []string{`C:\src`}},
+		{name: "token", value: "Bearer ${VERAPACK_TEST_TOKEN}", want: "Bearer t0ken-123", wantSecret: []string{"t0ken-123"}},
+		{name: "default unset", value: "${VERAPACK_TEST_UNSET:-main}", want: "main"},
+		{name: "default empty", value: "${VERAPACK_TEST_EMPTY:-main}", want: "main"},
+		{name: "empty", value: "a${VERAPACK_TEST_EMPTY}b", want: "ab"},
+		{name: "file", value: "Bearer ${file:" + tokenFile + "}", want: "Bearer s3cr3t", wantSecret: []string{"s3cr3t"}},
+		{name: "escaped", value: "echo $${HOME}", want: "echo ${HOME}"},
+		{name: "hook variable", value: "echo ${VERAPACK_BUILD_ID}", want: "echo ${VERAPACK_BUILD_ID}"},
+		{name: "unset", value: "${VERAPACK_TEST_UNSET}", wantErr: true},
+		{name: "missing file", value: "${file:" + tokenFile + ".missing}", wantErr: true},
+		{name: "unterminated", value: "${VERAPACK_TEST_SOURCE", wantErr: true},
+		{name: "invalid name", value: "${1VAR}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, secrets, err := interpolate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want || !slices.Equal(secrets, tt.wantSecret) {
+				t.Errorf("interpolate() = %q, %v, want %q, %v", got, secrets, tt.want, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestSetDefaultsInterpolation(t *testing.T) {
+	t.Setenv("VERAPACK_TEST_TOKEN", "t0ken-123")
+	t.Setenv("VERAPACK_TEST_SOURCE", "/src/api")
+
+	content := `default:
+  hooks:
+    pre_upload: notify --token ${VERAPACK_TEST_TOKEN}
+applications:
+  - app_name: API
+    package_source: ${VERAPACK_TEST_SOURCE}
+  - app_name: Web
+    package_source: ${VERAPACK_TEST_UNSET:-/src/web}/web
+`
+
+	c, err := SetDefaults([]byte(content))
+	if err != nil {
+		t.Fatalf("SetDefaults() error = %v", err)
+	}
+
+	if got := c.Applications[0].Hooks.PreUpload; got != "notify --token t0ken-123" {
+		t.Errorf("pre_upload = %q, want the interpolated default", got)
+	}
+
+	if got, want := c.Applications[0].Secrets, []string{"/src/api", "t0ken-123"}; !slices.Equal(got, want) {
+		t.Errorf("applications[0] secrets = %v, want %v", got, want)
+	}
+
+	if got, want := c.Applications[1].Secrets, []string{"t0ken-123"}; !slices.Equal(got, want) {
+		t.Errorf("applications[1] secrets = %v, want %v", got, want)
+	}
+}
+
+func TestReadConfigInterpolationErrors(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+
+	content := "applications:\n" +
+		"  - app_name: API\n    package_source: '" + dir + "'\n    type: directory\n" +
+		"  - app_name: Web\n    package_source: ${VERAPACK_TEST_UNSET}\n    type: directory\n"
+
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the applications that are run fail.
+	_, err := ReadConfig(configPath)
+
+	var unset *UnsetVariableError
+	if !errors.As(err, &unset) || unset.Name != "VERAPACK_TEST_UNSET" {
+		t.Errorf("ReadConfig() error = %v, want the unset variable", err)
+	}
+
+	if _, err = ReadConfig(configPath, "API"); err != nil {
+		t.Errorf("ReadConfig(API) error = %v, want no error for the application that is not run", err)
+	}
+}
@@ -2,22 +2,83 @@ package verapack
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
+// redacted replaces secrets in the log output.
+const redacted = "[REDACTED]"
+
+// minSecretLength is the length from which secrets are redacted wherever they appear. Shorter secrets are only
+// redacted where they are a whole word, because they would redact unrelated parts of the logs otherwise.
+const minSecretLength = 6
+
 // lineCounterWriter is a wrapper for an io.Writer, that counts the number of new line tokens that are being written.
 type lineCounterWriter struct {
 	writer          io.Writer
 	startLine       int
 	endLine         int
 	containsWarning bool
+	secrets         [][]byte
+
+	// mu guards pending, because the output of a child process is written concurrently from its stdout and stderr.
+	mu sync.Mutex
+	// pending is the incomplete last line of the output, if there are secrets. Secrets are only redacted from
+	// complete lines, because a secret can be split across writes.
+	pending []byte
 }
 
 func (c *lineCounterWriter) Write(p []byte) (n int, err error) {
+	if len(c.secrets) == 0 {
+		return c.write(p)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, p...)
+
+	if i := bytes.LastIndexByte(c.pending, '\n'); i != -1 {
+		lines := c.pending[:i+1]
+		c.pending = slices.Clone(c.pending[i+1:])
+
+		if _, err = c.write(c.redact(lines)); err != nil {
+			return 0, err
+		}
+	}
+
+	// The length of the original input has to be returned, otherwise the caller treats it as a short write.
+	return len(p), nil
+}
+
+// Flush writes the incomplete last line of the output.
+func (c *lineCounterWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pending) == 0 {
+		return nil
+	}
+
+	_, err := c.write(c.redact(c.pending))
+	c.pending = nil
+
+	return err
+}
+
+// redact replaces the secrets in p.
+func (c *lineCounterWriter) redact(p []byte) []byte {
+	return []byte(c.Redact(string(p)))
+}
+
+func (c *lineCounterWriter) write(p []byte) (n int, err error) {
 	if i := bytes.Count(p, []byte{'\n'}); i >= 0 {
 		c.endLine += i
 	}
@@ -33,6 +94,53 @@ func (c *lineCounterWriter) Write(p []byte) (n int, err error) {
 	return c.writer.Write(p)
 }
 
+// AddSecret makes the writer replace secret with a placeholder, before it is written to the log.
+func (c *lineCounterWriter) AddSecret(secret string) {
+	if secret != "" {
+		c.secrets = append(c.secrets, []byte(secret))
+	}
+}
+
+// Redact replaces the secrets that were added with [lineCounterWriter].AddSecret in s.
+func (c *lineCounterWriter) Redact(s string) string {
+	for _, secret := range c.secrets {
+		if len(secret) >= minSecretLength {
+			s = strings.ReplaceAll(s, string(secret), redacted)
+		} else {
+			s = replaceWord(s, string(secret), redacted)
+		}
+	}
+
+	return s
+}
+
+// replaceWord replaces the occurrences of word in s that are not part of a longer word with new.
+func replaceWord(s, word, new string) string {
+	var b strings.Builder
+
+	for {
+		k := strings.Index(s, word)
+		if k == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		end := k + len(word)
+		if (k > 0 && isWordByte(s[k-1])) || (end < len(s) && isWordByte(s[end])) {
+			b.WriteString(s[:end])
+		} else {
+			b.WriteString(s[:k] + new)
+		}
+
+		s = s[end:]
+	}
+}
+
+// isWordByte returns whether b is a letter, a digit or an underscore.
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
 func (c *lineCounterWriter) GetEndLine() int {
 	return c.endLine
 }
@@ -53,7 +161,7 @@ func newLineCounterWriter(startLine int, writer io.Writer) *lineCounterWriter {
 }
 
 // initializeLogWriter opens a log file in the user's temp directory and initializes the [lineCounterWriter],
-// with the file as the [io.Writer] input.
+// with the file as the [io.Writer] input. The returned function flushes the writer and closes the file.
 func initializeLogWriter(applicationName string) (*lineCounterWriter, func() error, error) {
 	path := filepath.Join(os.TempDir(), "verapack", "logs")
 	err := os.MkdirAll(path, 0600)
@@ -66,5 +174,28 @@ func initializeLogWriter(applicationName string) (*lineCounterWriter, func() err
 		return nil, nil, err
 	}
 
-	return newLineCounterWriter(0, file), file.Close, nil
+	w := newLineCounterWriter(0, file)
+
+	return w, func() error {
+		return errors.Join(w.Flush(), file.Close())
+	}, nil
+}
+
+// logToFile appends a timestamped message to the verapack log file in the user's temp directory. It
+// is used for events that are not specific to an application. Errors are ignored, because the log
+// file is not critical.
+func logToFile(message string) {
+	path := filepath.Join(os.TempDir(), "verapack", "logs")
+	if err := os.MkdirAll(path, 0600); err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(filepath.Join(path, "verapack.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	fmt.Fprintf(file, "%s %s\n", time.Now().Format(time.RFC3339), message)
 }
@@ -0,0 +1,346 @@
+package verapack
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// configMigrations upgrade the config files to the next config_version, in order. A migration is added, with the
+// next version, every time the config file changes in a way that older config files are no longer valid, e.g.
+// with [renameOption] or [removeOption]. Config files without a config_version are version 1.
+var configMigrations []configMigration
+
+// currentConfigVersion returns the config_version of the config files of this version of verapack, which is the
+// version of the last migration.
+func currentConfigVersion() int {
+	return latestConfigVersion(configMigrations)
+}
+
+// latestConfigVersion returns the version of the last of the migrations, or 1 if there are none.
+func latestConfigVersion(migrations []configMigration) int {
+	if len(migrations) == 0 {
+		return 1
+	}
+
+	return migrations[len(migrations)-1].version
+}
+
+// configMigration upgrades a config file from the previous config_version to version.
+type configMigration struct {
+	version     int
+	description string
+	// migrate changes the config file and returns a description of every change.
+	migrate func(d *configDocument) []string
+}
+
+// configDocument is a config file that is being migrated, with its comments.
+type configDocument struct {
+	doc      yaml.MapSlice
+	comments yaml.CommentMap
+}
+
+// OutdatedConfigError is the error of a config file that has to be migrated to the current config_version.
+type OutdatedConfigError struct {
+	Source  string   // Name of the config file.
+	Version int      // config_version of the config file.
+	Changes []string // Changes that the migration makes.
+	Command string   // Command that migrates the config file.
+}
+
+func (e *OutdatedConfigError) Error() string {
+	return fmt.Sprintf("config file %s has config_version %d and must be migrated to config_version %d, which will: %s. Run: %s", e.Source, e.Version, currentConfigVersion(), strings.Join(e.Changes, ", "), e.Command)
+}
+
+// checkConfigVersion returns an error if the config file was written for a newer version of verapack, or if it
+// is outdated and its migration changes it. Outdated config files that are not changed by their migration, e.g.
+// because they do not use the removed options, can still be used. command is the command that migrates the file.
+func checkConfigVersion(content []byte, source, command string) error {
+	_, changes, version, err := MigrateConfig(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	if len(changes) > 0 {
+		return &OutdatedConfigError{Source: source, Version: version, Changes: changes, Command: command}
+	}
+
+	return nil
+}
+
+// MigrateConfig runs the migrations of the config file from its config_version to the current one, and sets its
+// config_version. It returns the migrated config file, the changes, and the config_version before the migration.
+// The comments of the config file are kept, but blank lines are not.
+func MigrateConfig(content []byte) ([]byte, []string, int, error) {
+	return runConfigMigrations(content, configMigrations)
+}
+
+// runConfigMigrations runs the migrations, see [MigrateConfig]. The current config_version is the version of the last
+// migration.
+func runConfigMigrations(content []byte, migrations []configMigration) ([]byte, []string, int, error) {
+	current := latestConfigVersion(migrations)
+
+	d := configDocument{comments: yaml.CommentMap{}}
+
+	if err := yaml.UnmarshalWithOptions(content, &d.doc, yaml.UseOrderedMap(), yaml.CommentToMap(d.comments)); err != nil {
+		return nil, nil, 0, err
+	}
+
+	var c struct {
+		ConfigVersion int `yaml:"config_version"`
+	}
+
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, nil, 0, err
+	}
+
+	version := max(c.ConfigVersion, 1)
+	if version > current {
+		return nil, nil, version, fmt.Errorf("config_version %d is newer than the config_version %d of this version of verapack, please update verapack", version, current)
+	}
+
+	var changes []string
+
+	for _, migration := range migrations {
+		if migration.version <= version {
+			continue
+		}
+
+		for _, change := range migration.migrate(&d) {
+			changes = append(changes, fmt.Sprintf("%s (%s)", change, migration.description))
+		}
+	}
+
+	if version == current {
+		return content, nil, version, nil
+	}
+
+	if _, ok := lookupKey(d.doc, []string{"config_version"}); ok {
+		d.doc = setKey(d.doc, "config_version", current)
+	} else {
+		// config_version is added at the start, with the comments before the config file, e.g. the schema modeline.
+		if len(d.doc) > 0 {
+			d.moveHeadComments(fmt.Sprintf("$.%v", d.doc[0].Key), "$.config_version")
+		}
+
+		d.doc = slices.Insert(d.doc, 0, yaml.MapItem{Key: "config_version", Value: current})
+	}
+
+	migrated, err := yaml.MarshalWithOptions(d.doc, yaml.IndentSequence(true), yaml.WithComment(d.comments))
+	if err != nil {
+		return nil, nil, version, err
+	}
+
+	return migrated, changes, version, nil
+}
+
+// MigrateConfigFile migrates the config file, see [MigrateConfig]. The original config file is backed up next to it
+// first. It returns the path of the backup, or an empty string if the config file is up to date.
+func MigrateConfigFile(configPath string, dryRun bool) (string, []string, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	migrated, changes, version, err := MigrateConfig(content)
+	if err != nil || version == currentConfigVersion() || dryRun {
+		return "", changes, err
+	}
+
+	backupPath := fmt.Sprintf("%s.%s.bak", configPath, time.Now().Format("20060102-150405"))
+	if err = os.WriteFile(backupPath, content, 0600); err != nil {
+		return "", nil, err
+	}
+
+	if err = os.WriteFile(configPath, migrated, 0600); err != nil {
+		return backupPath, nil, err
+	}
+
+	return backupPath, changes, nil
+}
+
+// forEachOptions calls f with the default options and the options of every application, together with their path.
+// The options are replaced with the options that f returns.
+func (d *configDocument) forEachOptions(f func(options yaml.MapSlice, path string) yaml.MapSlice) {
+	for k, item := range d.doc {
+		switch item.Key {
+		case "default":
+			if options, ok := item.Value.(yaml.MapSlice); ok {
+				d.doc[k].Value = f(options, "default")
+			}
+		case "applications":
+			apps, _ := item.Value.([]any)
+			for i, app := range apps {
+				if options, ok := app.(yaml.MapSlice); ok {
+					apps[i] = f(options, fmt.Sprintf("applications[%d]", i))
+				}
+			}
+		}
+	}
+}
+
+// renameOption returns a migration that moves an option of the default options and the applications to a new key.
+// Nested keys are separated by dots, e.g. renameOption("hook_timeout", "hooks.timeout") moves the option into the
+// hooks section.
+func renameOption(from, to string) func(d *configDocument) []string {
+	return func(d *configDocument) []string {
+		var changes []string
+
+		d.forEachOptions(func(options yaml.MapSlice, path string) yaml.MapSlice {
+			var changed bool
+			if options, changed = d.moveKey(options, "$."+path, strings.Split(from, "."), strings.Split(to, ".")); changed {
+				changes = append(changes, fmt.Sprintf("rename %s.%s to %s.%s", path, from, path, to))
+			}
+
+			return options
+		})
+
+		return changes
+	}
+}
+
+// removeOption returns a migration that removes an option of the default options and the applications.
+func removeOption(key string) func(d *configDocument) []string {
+	return func(d *configDocument) []string {
+		var changes []string
+
+		d.forEachOptions(func(options yaml.MapSlice, path string) yaml.MapSlice {
+			var removed bool
+			if options, _, removed = deleteKey(options, strings.Split(key, ".")); removed {
+				d.moveComments("$."+path+"."+key, "")
+				changes = append(changes, fmt.Sprintf("remove %s.%s", path, key))
+			}
+
+			return options
+		})
+
+		return changes
+	}
+}
+
+// moveKey moves the value of the nested keys from to the nested keys to, and moves its comments. path is the
+// path of ms in the comments. An existing value of to is not overwritten.
+func (d *configDocument) moveKey(ms yaml.MapSlice, path string, from, to []string) (yaml.MapSlice, bool) {
+	if _, exists := lookupKey(ms, to); exists {
+		return ms, false
+	}
+
+	ms, value, ok := deleteKey(ms, from)
+	if !ok {
+		return ms, false
+	}
+
+	d.moveComments(path+"."+strings.Join(from, "."), path+"."+strings.Join(to, "."))
+
+	return setNestedKey(ms, to, value), true
+}
+
+// moveComments moves the comments of the value at the path and its nested values to the new path. The comments
+// are removed if to is empty.
+func (d *configDocument) moveComments(from, to string) {
+	for p, comments := range d.comments {
+		rest, ok := strings.CutPrefix(p, from)
+		if !ok || (rest != "" && rest[0] != '.' && rest[0] != '[') {
+			continue
+		}
+
+		delete(d.comments, p)
+
+		if to != "" {
+			d.comments[to+rest] = comments
+		}
+	}
+}
+
+// moveHeadComments moves the comments before the value at the path to the value at the new path.
+func (d *configDocument) moveHeadComments(from, to string) {
+	var head, other []*yaml.Comment
+
+	for _, comment := range d.comments[from] {
+		if comment.Position == yaml.CommentHeadPosition {
+			head = append(head, comment)
+		} else {
+			other = append(other, comment)
+		}
+	}
+
+	if len(head) > 0 {
+		d.comments[from] = other
+		d.comments[to] = append(d.comments[to], head...)
+	}
+}
+
+// lookupKey returns the value of the nested keys.
+func lookupKey(ms yaml.MapSlice, keys []string) (any, bool) {
+	for k, item := range ms {
+		if item.Key != keys[0] {
+			continue
+		}
+
+		if len(keys) == 1 {
+			return item.Value, true
+		}
+
+		nested, ok := ms[k].Value.(yaml.MapSlice)
+		if !ok {
+			return nil, false
+		}
+
+		return lookupKey(nested, keys[1:])
+	}
+
+	return nil, false
+}
+
+// deleteKey removes the nested keys and returns their value. Maps that become empty are removed as well.
+func deleteKey(ms yaml.MapSlice, keys []string) (yaml.MapSlice, any, bool) {
+	k := slices.IndexFunc(ms, func(item yaml.MapItem) bool { return item.Key == keys[0] })
+	if k == -1 {
+		return ms, nil, false
+	}
+
+	if len(keys) == 1 {
+		value := ms[k].Value
+		return slices.Delete(ms, k, k+1), value, true
+	}
+
+	nested, ok := ms[k].Value.(yaml.MapSlice)
+	if !ok {
+		return ms, nil, false
+	}
+
+	nested, value, ok := deleteKey(nested, keys[1:])
+	if ok && len(nested) == 0 {
+		return slices.Delete(ms, k, k+1), value, true
+	}
+
+	ms[k].Value = nested
+
+	return ms, value, ok
+}
+
+// setNestedKey sets the value of the nested keys, and creates the maps that do not exist.
+func setNestedKey(ms yaml.MapSlice, keys []string, value any) yaml.MapSlice {
+	if len(keys) == 1 {
+		return setKey(ms, keys[0], value)
+	}
+
+	nested, _ := lookupKey(ms, keys[:1])
+	nestedMap, _ := nested.(yaml.MapSlice)
+
+	return setKey(ms, keys[0], setNestedKey(nestedMap, keys[1:], value))
+}
+
+// setKey sets the value of the key, or adds the key if it does not exist.
+func setKey(ms yaml.MapSlice, key string, value any) yaml.MapSlice {
+	if k := slices.IndexFunc(ms, func(item yaml.MapItem) bool { return item.Key == key }); k != -1 {
+		ms[k].Value = value
+		return ms
+	}
+
+	return append(ms, yaml.MapItem{Key: key, Value: value})
+}
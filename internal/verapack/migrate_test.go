@@ -0,0 +1,139 @@
+package verapack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+// testMigrations are the migrations of the tests, because verapack does not have any migrations yet.
+var testMigrations = []configMigration{
+	{version: 2, description: "remove the legacy option", migrate: removeOption("legacy")},
+	{version: 3, description: "move hook_timeout into hooks", migrate: renameOption("hook_timeout", "hooks.timeout")},
+}
+
+// setTestMigrations replaces the migrations of verapack with testMigrations until the end of the test.
+func setTestMigrations(t *testing.T) {
+	migrations := configMigrations
+	configMigrations = testMigrations
+
+	t.Cleanup(func() { configMigrations = migrations })
+}
+
+func TestMigrateConfig(t *testing.T) {
+	content := `# yaml-language-server: $schema=./config.schema.json
+default:
+  # Not used anymore.
+  legacy: true
+  verbose: true
+applications:
+  - app_name: API # The API.
+    package_source: C:\src\api
+    legacy: false
+    hook_timeout: 5
+`
+
+	migrated, changes, version, err := runConfigMigrations([]byte(content), testMigrations)
+	if err != nil {
+		t.Fatalf("runConfigMigrations() error = %v", err)
+	}
+
+	if version != 1 || len(changes) != 3 {
+		t.Errorf("runConfigMigrations() version = %d, changes = %v, want version 1 and 3 changes", version, changes)
+	}
+
+	got := string(migrated)
+	if strings.Contains(got, "legacy") || strings.Contains(got, "Not used anymore") {
+		t.Errorf("runConfigMigrations() did not remove legacy:\n%s", got)
+	}
+
+	for _, want := range []string{"# yaml-language-server: $schema=./config.schema.json\nconfig_version: 3\n", "app_name: API # The API.", "verbose: true", "timeout: 5"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("runConfigMigrations() does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if _, changes, _, err = runConfigMigrations(migrated, testMigrations); err != nil || len(changes) > 0 {
+		t.Errorf("runConfigMigrations() of the migrated config file = %v, %v, want no changes", changes, err)
+	}
+
+	// Only the migrations after the config_version of the config file are run.
+	if _, changes, version, err = runConfigMigrations([]byte("config_version: 2\ndefault:\n  legacy: true\n  hook_timeout: 5\n"), testMigrations); err != nil || version != 2 || len(changes) != 1 {
+		t.Errorf("runConfigMigrations() of config_version 2 = %d, %v, %v, want 1 change", version, changes, err)
+	}
+
+	if _, _, _, err = runConfigMigrations([]byte("config_version: 4\n"), testMigrations); err == nil {
+		t.Error("runConfigMigrations() of a newer config_version did not return an error")
+	}
+
+	if _, changes, version, err = MigrateConfig(configFileBytes); err != nil || version != currentConfigVersion() || len(changes) > 0 {
+		t.Errorf("MigrateConfig() of the template = %d, %v, %v, want the current config_version", version, changes, err)
+	}
+}
+
+func TestRenameOption(t *testing.T) {
+	content := `default:
+  hook_timeout: 10 # Minutes.
+applications:
+  - app_name: API
+    hooks:
+      pre_scan: make
+    hook_timeout: 5
+`
+
+	d := configDocument{comments: yaml.CommentMap{}}
+	if err := yaml.UnmarshalWithOptions([]byte(content), &d.doc, yaml.UseOrderedMap(), yaml.CommentToMap(d.comments)); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := renameOption("hook_timeout", "hooks.timeout")(&d)
+
+	if len(changes) != 2 {
+		t.Errorf("renameOption() changes = %v, want 2 changes", changes)
+	}
+
+	if got, _ := lookupKey(d.doc, []string{"default", "hooks", "timeout"}); got != uint64(10) {
+		t.Errorf("default.hooks.timeout = %v, want 10", got)
+	}
+
+	if got, _ := lookupKey(d.doc, []string{"applications"}); len(got.([]any)[0].(yaml.MapSlice)) != 2 {
+		t.Errorf("applications = %v, want hook_timeout to be moved into hooks", got)
+	}
+
+	if _, ok := d.comments["$.default.hooks.timeout"]; !ok {
+		t.Errorf("comments = %v, want the comment of default.hooks.timeout", d.comments)
+	}
+}
+
+func TestCheckConfigVersion(t *testing.T) {
+	setTestMigrations(t)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+
+	os.WriteFile(configPath, []byte("applications:\n  - app_name: API\n    package_source: '"+dir+"'\n    type: directory\n    legacy: true\n"), 0600)
+
+	_, err := ReadConfig(configPath)
+
+	var outdated *OutdatedConfigError
+	if !errors.As(err, &outdated) || outdated.Version != 1 {
+		t.Fatalf("ReadConfig() error = %v, want an outdated config file", err)
+	}
+
+	backupPath, _, err := MigrateConfigFile(configPath, false)
+	if err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+
+	if backup, _ := os.ReadFile(backupPath); !strings.Contains(string(backup), "legacy: true") {
+		t.Errorf("backup = %q, want the original config file", backup)
+	}
+
+	if _, err = ReadConfig(configPath); err != nil {
+		t.Errorf("ReadConfig() of the migrated config file error = %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package verapack
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	defaultWrapperBaseURL  = "https://repo1.maven.org/maven2"
+	defaultPackagerBaseURL = "https://tools.veracode.com/veracode-cli"
+
+	// wrapperArtifactPath is the path of the Java API wrapper artifact, relative to the root of a Maven repository.
+	wrapperArtifactPath = "com/veracode/vosp/api/wrappers/vosp-api-wrappers-java"
+)
+
+// Mirror contains the base URL of an artifact repository that proxies one of the Veracode tools,
+// as well as any credentials required to access it.
+//
+// If Token is set, it will be sent as a bearer token. Otherwise, if Username is set, basic
+// authentication will be used.
+type Mirror struct {
+	URL      string `yaml:"url" validate:"omitempty,url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Token    string `yaml:"token"`
+}
+
+// Mirrors contains the download mirrors for the different tools that verapack installs.
+type Mirrors struct {
+	// Wrapper is the base URL of a Maven repository that proxies Maven Central.
+	Wrapper Mirror `yaml:"wrapper"`
+	// Packager is the base URL that proxies https://tools.veracode.com/veracode-cli.
+	Packager Mirror `yaml:"packager"`
+}
+
+// baseURL returns the mirror's URL, or defaultURL if the mirror is not set.
+func (m Mirror) baseURL(defaultURL string) (*url.URL, error) {
+	if m.URL == "" {
+		return url.Parse(defaultURL)
+	}
+
+	return url.Parse(m.URL)
+}
+
+// authenticate adds the mirror's credentials to the request.
+func (m Mirror) authenticate(req *http.Request) {
+	switch {
+	case m.Token != "":
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	case m.Username != "":
+		req.SetBasicAuth(m.Username, m.Password)
+	}
+}
+
+// get sends an authenticated GET request to the provided URL.
+func (m Mirror) get(client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m.authenticate(req)
+
+	return client.Do(req)
+}
+
+// setFromEnv overrides the mirror's fields with the values of the environment variables
+// that start with prefix.
+func (m *Mirror) setFromEnv(prefix string) {
+	if v, ok := os.LookupEnv(prefix + "_URL"); ok {
+		m.URL = v
+	}
+
+	if v, ok := os.LookupEnv(prefix + "_USERNAME"); ok {
+		m.Username = v
+	}
+
+	if v, ok := os.LookupEnv(prefix + "_PASSWORD"); ok {
+		m.Password = v
+	}
+
+	if v, ok := os.LookupEnv(prefix + "_TOKEN"); ok {
+		m.Token = v
+	}
+}
+
+// validateURL returns an error if the mirror's URL is set and is not valid, using the same validation as the
+// config file. The error names the environment variable if the URL was set with it.
+func (m Mirror) validateURL(name, prefix string) error {
+	if m.URL == "" {
+		return nil
+	}
+
+	if err := validator.New().Var(m.URL, "url"); err != nil {
+		if _, ok := os.LookupEnv(prefix + "_URL"); ok {
+			return fmt.Errorf("%s_URL: '%s' is not a valid URL", prefix, m.URL)
+		}
+
+		return fmt.Errorf("config validation error at mirrors.%s.url: '%s' is not a valid URL", name, m.URL)
+	}
+
+	return nil
+}
+
+// LoadMirrors reads the mirrors section from the config file and overrides the values with
+// any of the below environment variables that are set:
+//
+//	VERAPACK_WRAPPER_MIRROR_[URL|USERNAME|PASSWORD|TOKEN]
+//	VERAPACK_PACKAGER_MIRROR_[URL|USERNAME|PASSWORD|TOKEN]
+//
+// The URLs of the mirrors are validated, but the rest of the config file is not validated, because the tools are installed before the
+// user has added their applications. A missing config file is not an error.
+func LoadMirrors(configPath string) (Mirrors, error) {
+	var c struct {
+		Mirrors Mirrors `yaml:"mirrors"`
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Mirrors{}, err
+	}
+
+	if err = yaml.Unmarshal(content, &c); err != nil {
+		return Mirrors{}, err
+	}
+
+	if _, errs := interpolateConfig(&c); len(errs) > 0 {
+		return Mirrors{}, errors.Join(errs...)
+	}
+
+	c.Mirrors.Wrapper.setFromEnv("VERAPACK_WRAPPER_MIRROR")
+	c.Mirrors.Packager.setFromEnv("VERAPACK_PACKAGER_MIRROR")
+
+	if err = c.Mirrors.Wrapper.validateURL("wrapper", "VERAPACK_WRAPPER_MIRROR"); err != nil {
+		return Mirrors{}, err
+	}
+
+	if err = c.Mirrors.Packager.validateURL("packager", "VERAPACK_PACKAGER_MIRROR"); err != nil {
+		return Mirrors{}, err
+	}
+
+	return c.Mirrors, nil
+}
@@ -0,0 +1,94 @@
+package verapack
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMirrors(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(configPath, []byte(`
+mirrors:
+  wrapper:
+    url: https://nexus.example.com/repository/maven-central
+    username: user
+    password: pass
+  packager:
+    url: https://nexus.example.com/repository/veracode-cli
+applications:
+  - app_name: Test`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("VERAPACK_PACKAGER_MIRROR_TOKEN", "token")
+	t.Setenv("VERAPACK_WRAPPER_MIRROR_PASSWORD", "override")
+
+	got, err := LoadMirrors(configPath)
+	if err != nil {
+		t.Fatalf("LoadMirrors() error = %v", err)
+	}
+
+	want := Mirrors{
+		Wrapper:  Mirror{URL: "https://nexus.example.com/repository/maven-central", Username: "user", Password: "override"},
+		Packager: Mirror{URL: "https://nexus.example.com/repository/veracode-cli", Token: "token"},
+	}
+
+	if got != want {
+		t.Errorf("LoadMirrors() = %+v, want %+v", got, want)
+	}
+
+	got, err = LoadMirrors(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadMirrors() with missing file error = %v", err)
+	}
+
+	if got.Packager.Token != "token" || got.Wrapper.URL != "" {
+		t.Errorf("LoadMirrors() with missing file = %+v", got)
+	}
+}
+
+func TestLoadMirrorsInvalidURL(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(configPath, []byte("mirrors:\n  wrapper:\n    url: nexus.example.com/maven\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadMirrors(configPath); err == nil || !strings.Contains(err.Error(), "mirrors.wrapper.url") {
+		t.Errorf("LoadMirrors() error = %v, want an error for the URL in the config file", err)
+	}
+
+	t.Setenv("VERAPACK_WRAPPER_MIRROR_URL", "https://nexus.example.com/maven")
+	t.Setenv("VERAPACK_PACKAGER_MIRROR_URL", "://nexus")
+
+	if _, err := LoadMirrors(configPath); err == nil || !strings.Contains(err.Error(), "VERAPACK_PACKAGER_MIRROR_URL") {
+		t.Errorf("LoadMirrors() error = %v, want an error for the URL in the environment variable", err)
+	}
+}
+
+func TestMirrorAuthenticate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mirror Mirror
+		want   string
+	}{
+		{name: "no credentials", mirror: Mirror{}, want: ""},
+		{name: "basic", mirror: Mirror{Username: "user", Password: "pass"}, want: "Basic dXNlcjpwYXNz"},
+		{name: "token takes precedence", mirror: Mirror{Username: "user", Password: "pass", Token: "abc"}, want: "Bearer abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "https://nexus.example.com", nil)
+			tt.mirror.authenticate(req)
+
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("authenticate() Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,13 @@ package verapack
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -51,31 +53,59 @@ func cloneOptionsToArgs(options Options, outputDirPath string) []string {
 	r := make([]string, 0, 8)
 	r = append(r, "clone", "--single-branch", "--depth", "1")
 
+	if len(options.SparsePaths) > 0 {
+		// The commit is checked out after the sparse checkout has been set, see [setSparseCheckout].
+		r = append(r, "--no-checkout", "--filter=blob:none")
+	}
+
 	if len(options.Branch) > 0 {
 		r = append(r, "--branch", options.Branch)
 	}
 
-	switch options.Type {
-	case Directory:
-		r = append(r, "file://"+options.PackageSource, outputDirPath)
-	case Repo:
-		r = append(r, options.PackageSource, outputDirPath)
-	}
+	r = append(r, cloneSource(options), outputDirPath)
 
 	return r
 }
 
+// cloneSource returns the URL of the repository to clone.
+func cloneSource(options Options) string {
+	if options.Type == Directory {
+		return "file://" + options.PackageSource
+	}
+
+	return options.PackageSource
+}
+
+// packageSourceDir returns the directory that the packager is run against, which is [Options].SourceSubdir
+// inside [Options].PackageSource, if it is set.
+func packageSourceDir(options Options) (string, error) {
+	if options.SourceSubdir == "" {
+		return options.PackageSource, nil
+	}
+
+	dir := filepath.Join(options.PackageSource, filepath.FromSlash(options.SourceSubdir))
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("source_subdir '%s' is not a directory in the source", options.SourceSubdir)
+	}
+
+	return dir, nil
+}
+
 // PackageApplication runs the Veracode auto-packager using the provided PackageOptions,
 // and returns a list of the artefact paths and any errors encountered.
 //
+// env is appended to the environment of the CLI, see [ProfileClient].Env.
+//
 // writer can optionally be provided to write log output to an additional location.
-func PackageApplication(options Options, outputDirPath string, writer io.Writer) ([]string, string, error) {
+func PackageApplication(options Options, outputDirPath string, env []string, writer io.Writer) ([]string, string, error) {
 	path, err := exec.LookPath("veracode")
 	if err != nil {
 		return nil, err.Error(), err
 	}
 
 	cmd := exec.Command(path, packageOptionsToArgs(options, outputDirPath)...)
+	cmd.Env = withEnv(env)
 
 	var outBuffer bytes.Buffer
 
@@ -103,16 +133,108 @@ func PackageApplication(options Options, outputDirPath string, writer io.Writer)
 }
 
 // CloneRepository creates a shallow clone of a remote or local repository into the temp
-// directory. CloneRepository returns the log output and any error.
+// directory. CloneRepository returns the metadata of the cloned commit, the log output and any error.
+//
+// If [Options].Ref is set, only that ref is fetched and checked out, see [cloneRef]. Otherwise,
+// [Options].Branch or the default branch is cloned.
+//
+// If [Options].CloneCache is set for a remote repository, the clone is made from a mirror in
+// the clone cache instead, see [cloneFromCache].
+//
+// If [Options].GitAuth is set, its credentials are used for all of the git commands, see [GitAuth].Env.
 //
 // writer can optionally be provided to write log output to an additional location.
-func CloneRepository(options Options, outputDirPath string, writer io.Writer) (string, error) {
+func CloneRepository(options Options, outputDirPath string, writer io.Writer) (GitInfo, string, error) {
 	path, err := exec.LookPath("git")
 	if err != nil {
-		return "", err
+		return GitInfo{}, "", err
+	}
+
+	var env []string
+	if options.GitAuth != nil {
+		if env, err = options.GitAuth.Env(); err != nil {
+			return GitInfo{}, err.Error(), err
+		}
+	}
+
+	clone := func(source string) (GitInfo, string, error) {
+		var info GitInfo
+		var out string
+		var err error
+
+		if options.Ref != "" {
+			info, out, err = cloneRef(path, env, source, options.Ref, outputDirPath, options.SparsePaths, writer)
+		} else {
+			o := options
+			o.PackageSource, o.Type = source, Repo
+			out, err = runGitCommand(path, env, writer, cloneOptionsToArgs(o, outputDirPath)...)
+			info.Branch = options.Branch
+
+			if err == nil && len(options.SparsePaths) > 0 {
+				var sparseOut string
+				sparseOut, err = setSparseCheckout(path, env, outputDirPath, options.SparsePaths, writer)
+				out += sparseOut
+
+				if err == nil {
+					sparseOut, err = runGitCommand(path, env, writer, "-C", outputDirPath, "checkout", "--quiet")
+					out += sparseOut
+				}
+			}
+		}
+
+		if err != nil {
+			return info, out, err
+		}
+
+		if options.Ref == "" && info.Branch == "" {
+			// The default branch was cloned.
+			branch, err := runGitCommand(path, env, nil, "-C", outputDirPath, "rev-parse", "--abbrev-ref", "HEAD")
+			if err != nil {
+				return info, out + branch, err
+			}
+
+			info.Branch = strings.TrimSpace(branch)
+		}
+
+		if info.SHA == "" {
+			sha, err := runGitCommand(path, env, nil, "-C", outputDirPath, "rev-parse", "HEAD")
+			if err != nil {
+				return info, out + sha, err
+			}
+
+			info.SHA = strings.TrimSpace(sha)
+		}
+
+		// The resolved commit is logged, so that the scan can be traced back to the exact source.
+		msg := fmt.Sprintf("Checked out commit: %s\n", info.SHA)
+		if options.Ref != "" {
+			msg = fmt.Sprintf("Resolved ref '%s' to commit: %s\n", options.Ref, info.SHA)
+		}
+
+		if writer != nil {
+			fmt.Fprint(writer, msg)
+		}
+
+		checkoutOut, err := completeCheckout(path, env, options, source, outputDirPath, writer)
+
+		return info, out + msg + checkoutOut, err
 	}
 
-	cmd := exec.Command(path, cloneOptionsToArgs(options, outputDirPath)...)
+	if options.Type == Repo && options.CloneCache != nil && *options.CloneCache {
+		return cloneFromCache(path, env, options, writer, clone)
+	}
+
+	return clone(cloneSource(options))
+}
+
+// runGitCommand runs git with the provided arguments and returns the log output and any error.
+//
+// env is appended to the environment of git, see [GitAuth].Env.
+//
+// writer can optionally be provided to write log output to an additional location.
+func runGitCommand(gitPath string, env []string, writer io.Writer, args ...string) (string, error) {
+	cmd := exec.Command(gitPath, args...)
+	cmd.Env = withEnv(env)
 
 	var outBuffer bytes.Buffer
 
@@ -123,7 +245,7 @@ func CloneRepository(options Options, outputDirPath string, writer io.Writer) (s
 		cmd.Stderr, cmd.Stdout = &outBuffer, &outBuffer
 	}
 
-	err = cmd.Run()
+	err := cmd.Run()
 	out := outBuffer.String()
 
 	if err != nil {
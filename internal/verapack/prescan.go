@@ -0,0 +1,237 @@
+package verapack
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/DanCreative/verapack/internal/components/reportcard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// viewportPrescan is the name of the report card viewport that renders the output of the Upload task, see [uploadOutput].
+const viewportPrescan = "prescan"
+
+// prescanResults contains the modules that were found in the uploaded artefacts by the prescan.
+type prescanResults struct {
+	XMLName xml.Name        `xml:"prescanresults"`
+	BuildId string          `xml:"build_id,attr"`
+	Modules []prescanModule `xml:"module"`
+}
+
+type prescanModule struct {
+	Id             string             `xml:"id,attr"`
+	Name           string             `xml:"name,attr"`
+	Platform       string             `xml:"platform,attr"`
+	Size           string             `xml:"size,attr"`
+	Status         string             `xml:"status,attr"`
+	HasFatalErrors bool               `xml:"has_fatal_errors,attr"`
+	IsDependency   bool               `xml:"is_dependency,attr"`
+	Issues         []prescanIssue     `xml:"issue"`
+	FileIssues     []prescanFileIssue `xml:"file_issue"`
+}
+
+type prescanIssue struct {
+	Details string `xml:"details,attr"`
+}
+
+type prescanFileIssue struct {
+	Filename string `xml:"filename,attr"`
+	Details  string `xml:"details,attr"`
+}
+
+type prescanResultsOptions struct {
+	AppId     int `url:"app_id,omitempty"`
+	BuildId   int `url:"build_id,omitempty"`
+	SandboxId int `url:"sandbox_id,omitempty"`
+}
+
+// getPrescanResults returns the prescan results of the build.
+//
+// Documentation Reference: https://docs.veracode.com/r/r_getprescanresults
+func getPrescanResults(ctx context.Context, client *veracode.Client, options prescanResultsOptions) (prescanResults, error) {
+	req, err := client.NewRequest(ctx, "/api/5.0/getprescanresults.do", http.MethodGet, nil, true)
+	if err != nil {
+		return prescanResults{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/xml")
+	req.URL.RawQuery = veracode.QueryEncode(options)
+
+	var result prescanResults
+
+	if _, err = client.Do(req, &result); err != nil {
+		return prescanResults{}, err
+	}
+
+	return result, nil
+}
+
+// moduleSelected returns whether the top-level module is selected for the scan by the include_modules and
+// exclude_modules patterns, using the same rules as the API wrapper.
+func moduleSelected(include, exclude []string, name string) bool {
+	if len(include) > 0 && !matchAnyModulePattern(include, name) {
+		return false
+	}
+
+	return !matchAnyModulePattern(exclude, name)
+}
+
+// matchAnyModulePattern reports whether the module name matches any of the patterns. Like the API wrapper,
+// the patterns are case-sensitive and the * wildcard matches 0 or more characters.
+func matchAnyModulePattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, "*")
+		for k := range parts {
+			parts[k] = regexp.QuoteMeta(parts[k])
+		}
+
+		if regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// uploadOutput is the output of the Upload task. It contains the log output of the API wrapper and the prescan
+// results of the build, which are rendered by [prescanViewport].
+type uploadOutput struct {
+	log            string
+	prescan        *prescanResults
+	prescanErr     error
+	includeModules []string
+	excludeModules []string
+}
+
+var (
+	prescanHeading = lipgloss.NewStyle().Bold(true)
+	prescanFatal   = lipgloss.NewStyle().Foreground(red)
+	prescanWarning = lipgloss.NewStyle().Foreground(orange)
+	prescanMuted   = darkGrayForeground
+)
+
+// String renders the modules, their fatal errors and missing supporting files, followed by the log output.
+func (o uploadOutput) String() string {
+	var b strings.Builder
+
+	switch {
+	case o.prescanErr != nil:
+		fmt.Fprintf(&b, "%s\n%s\n\n", prescanHeading.Render("Prescan"), prescanWarning.Render("The prescan results could not be retrieved: "+o.prescanErr.Error()))
+
+	case o.prescan != nil:
+		fmt.Fprintf(&b, "%s\n", prescanHeading.Render("Prescan modules"))
+
+		for _, module := range o.prescan.Modules {
+			if module.IsDependency {
+				continue
+			}
+
+			selected := "default"
+			if len(o.includeModules) > 0 || len(o.excludeModules) > 0 {
+				selected = "not selected"
+				if moduleSelected(o.includeModules, o.excludeModules, module.Name) && !module.HasFatalErrors {
+					selected = "selected"
+				}
+			}
+
+			line := fmt.Sprintf("%s (%s, %s) %s", module.Name, module.Platform, module.Size, prescanMuted.Render("["+selected+"]"))
+
+			switch {
+			case module.HasFatalErrors:
+				fmt.Fprintf(&b, "%s %s\n", prescanFatal.Render("✗"), line)
+			case module.Status != "" && module.Status != "OK":
+				fmt.Fprintf(&b, "%s %s\n", prescanWarning.Render("⚠"), line)
+			default:
+				fmt.Fprintf(&b, "✓ %s\n", line)
+			}
+
+			if module.Status != "" && module.Status != "OK" {
+				fmt.Fprintf(&b, "    Status: %s\n", module.Status)
+			}
+
+			for _, issue := range module.Issues {
+				fmt.Fprintf(&b, "    %s\n", issue.Details)
+			}
+
+			for _, issue := range module.FileIssues {
+				fmt.Fprintf(&b, "    %s: %s\n", issue.Filename, issue.Details)
+			}
+		}
+
+		var dependencies int
+		for _, module := range o.prescan.Modules {
+			if module.IsDependency {
+				dependencies++
+			}
+		}
+
+		fmt.Fprintf(&b, "%s\n\n", prescanMuted.Render(fmt.Sprintf("%d dependency modules are not shown.", dependencies)))
+	}
+
+	fmt.Fprintf(&b, "%s\n%s", prescanHeading.Render("Log"), o.log)
+
+	return b.String()
+}
+
+var _ reportcard.Viewport = (*prescanViewport)(nil)
+
+// prescanViewport renders the [uploadOutput] of the Upload task in the report card.
+type prescanViewport struct {
+	reportcard.DefaultViewport
+}
+
+func (p *prescanViewport) Init(width int, height int, inputData any) tea.Cmd {
+	return p.DefaultViewport.Init(width, height, renderUploadOutput(inputData))
+}
+
+func (p *prescanViewport) SetContent(inputData any) {
+	p.DefaultViewport.SetContent(renderUploadOutput(inputData))
+}
+
+func (p prescanViewport) Update(msg tea.Msg) (reportcard.Viewport, tea.Cmd) {
+	v, cmd := p.DefaultViewport.Update(msg)
+	p.DefaultViewport = *v.(*reportcard.DefaultViewport)
+	return &p, cmd
+}
+
+// renderUploadOutput converts the output of the Upload task to the string that is shown in the viewport.
+func renderUploadOutput(inputData any) any {
+	if o, ok := inputData.(uploadOutput); ok {
+		return o.String()
+	}
+
+	return inputData
+}
+
+// newUploadOutput returns the output of the Upload task, with the prescan results of the build that was created
+// by the API wrapper, if its ID could be found in the log output.
+func newUploadOutput(ctx context.Context, client *veracode.Client, options Options, log string) uploadOutput {
+	o := uploadOutput{log: log, includeModules: options.IncludeModules, excludeModules: options.ExcludeModules}
+
+	buildId, _ := strconv.Atoi(parseBuildId(log))
+	if buildId == 0 {
+		return o
+	}
+
+	if options.AppId == 0 {
+		if options.AppId, _, o.prescanErr = getApplicationIdentifiers(ctx, client, options.AppName); o.prescanErr != nil {
+			return o
+		}
+	}
+
+	prescan, err := getPrescanResults(ctx, client, prescanResultsOptions{AppId: options.AppId, BuildId: buildId, SandboxId: options.SandboxId})
+	if err != nil {
+		o.prescanErr = err
+		return o
+	}
+
+	o.prescan = &prescan
+	return o
+}
@@ -0,0 +1,58 @@
+package verapack
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestUploadOutput(t *testing.T) {
+	doc := `<prescanresults account_id="1" app_id="2" build_id="3">
+	<module id="10" name="app.war" platform="JVM / Java J2SE 17 / JAVAC_17" size="12MB" status="OK" has_fatal_errors="false" is_dependency="false"/>
+	<module id="11" name="Web.dll" platform=".NET / Windows" size="1MB" status="(Fatal)PDB Files Missing - 1 File" has_fatal_errors="true" is_dependency="false">
+		<issue details="No supporting files or PDB files"/>
+		<file_issue filename="Web.pdb" details="Not Found (Required)"/>
+	</module>
+	<module id="12" name="commons-lang3.jar" platform="JVM" size="600KB" status="OK" has_fatal_errors="false" is_dependency="true"/>
+</prescanresults>`
+
+	var prescan prescanResults
+	if err := xml.Unmarshal([]byte(doc), &prescan); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+
+	o := uploadOutput{log: "uploaded", prescan: &prescan, includeModules: []string{"*.war", "*.dll"}}
+	got := o.String()
+
+	for _, want := range []string{"app.war", "[selected]", "Web.dll", "[not selected]", "Web.pdb: Not Found (Required)", "1 dependency modules", "uploaded"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("uploadOutput.String() does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "commons-lang3.jar") {
+		t.Errorf("uploadOutput.String() contains a dependency module:\n%s", got)
+	}
+}
+
+func TestModuleSelected(t *testing.T) {
+	tests := []struct {
+		include []string
+		exclude []string
+		name    string
+		want    bool
+	}{
+		{nil, nil, "app.war", true},
+		{[]string{"*.war"}, nil, "app.war", true},
+		{[]string{"*.war"}, nil, "app.jar", false},
+		{[]string{"*.war"}, nil, "app.WAR", false},
+		{nil, []string{"test*"}, "tests.jar", false},
+		{[]string{"app*"}, []string{"*.js"}, "app.js", false},
+		{[]string{"a.b"}, nil, "axb", false},
+	}
+	for _, tt := range tests {
+		if got := moduleSelected(tt.include, tt.exclude, tt.name); got != tt.want {
+			t.Errorf("moduleSelected(%v, %v, %q) = %v, want %v", tt.include, tt.exclude, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package verapack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RefLatestTag is the value of [Options].Ref that selects the tag with the highest version.
+const RefLatestTag = "latest-tag"
+
+// shaPattern matches a full or abbreviated commit SHA.
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// GitInfo contains the metadata of the commit that was cloned. It is set after the clone step.
+type GitInfo struct {
+	SHA    string // Full SHA of the cloned commit.
+	Tag    string // Name of the tag, if the commit was selected by a tag.
+	Branch string // Name of the branch, if the commit was selected by a branch.
+}
+
+// ShortSHA returns the abbreviated SHA of the cloned commit.
+func (g GitInfo) ShortSHA() string {
+	if len(g.SHA) > 7 {
+		return g.SHA[:7]
+	}
+
+	return g.SHA
+}
+
+// remoteRef is a single line of the git ls-remote output.
+type remoteRef struct {
+	sha  string
+	name string
+}
+
+// lsRemote lists the refs of the remote repository that match the patterns.
+func lsRemote(gitPath string, env []string, source string, flags []string, patterns ...string) ([]remoteRef, error) {
+	args := append(append([]string{"ls-remote"}, flags...), source)
+
+	out, err := runGitCommand(gitPath, env, nil, append(args, patterns...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, out)
+	}
+
+	var refs []remoteRef
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		sha, name, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || strings.HasSuffix(name, "^{}") {
+			continue
+		}
+
+		refs = append(refs, remoteRef{sha: sha, name: name})
+	}
+
+	return refs, nil
+}
+
+// cloneRef creates a checkout of a single ref of the source repository in outputDirPath.
+//
+// ref can be a branch, a tag, a full or abbreviated commit SHA or [RefLatestTag]. Branches, tags and full SHAs
+// are fetched with a depth of 1. An abbreviated SHA can not be fetched directly, therefore the full history
+// is fetched to resolve it. If sparsePaths is not empty, only those directories are checked out.
+func cloneRef(gitPath string, env []string, source, ref, outputDirPath string, sparsePaths []string, writer io.Writer) (GitInfo, string, error) {
+	var info GitInfo
+	var log strings.Builder
+
+	run := func(args ...string) error {
+		out, err := runGitCommand(gitPath, env, writer, append([]string{"-C", outputDirPath}, args...)...)
+		log.WriteString(out)
+		return err
+	}
+
+	if err := os.MkdirAll(outputDirPath, 0700); err != nil {
+		return info, err.Error(), err
+	}
+
+	if err := run("init", "--quiet"); err != nil {
+		return info, log.String(), err
+	}
+
+	if err := run("remote", "add", "origin", source); err != nil {
+		return info, log.String(), err
+	}
+
+	target, isTag, err := resolveRemoteRef(gitPath, env, source, ref)
+	if err != nil {
+		log.WriteString(err.Error())
+		return info, log.String(), errCloningErr
+	}
+
+	if isTag {
+		info.Tag = target
+	} else {
+		info.Branch = target
+	}
+
+	switch {
+	case target != "":
+		err = run("fetch", "--depth", "1", "origin", target)
+	case len(ref) == 40:
+		// Fetching a SHA directly requires the server to allow it. Fall back to the full history if it does not.
+		if err = run("fetch", "--depth", "1", "origin", ref); err != nil {
+			err = run("fetch", "--tags", "origin")
+		}
+	default:
+		err = run("fetch", "--tags", "origin")
+	}
+
+	if err != nil {
+		return info, log.String(), err
+	}
+
+	commit := "FETCH_HEAD"
+	if target == "" {
+		commit = ref + "^{commit}"
+	}
+
+	if len(sparsePaths) > 0 {
+		out, err := setSparseCheckout(gitPath, env, outputDirPath, sparsePaths, writer)
+		log.WriteString(out)
+		if err != nil {
+			return info, log.String(), err
+		}
+	}
+
+	if err = run("-c", "advice.detachedHead=false", "checkout", "--quiet", "--detach", commit); err != nil {
+		return info, log.String(), err
+	}
+
+	sha, err := runGitCommand(gitPath, env, nil, "-C", outputDirPath, "rev-parse", "HEAD")
+	if err != nil {
+		return info, log.String() + sha, err
+	}
+
+	info.SHA = strings.TrimSpace(sha)
+
+	return info, log.String(), nil
+}
+
+// resolveRemoteRef determines what ref refers to on the remote repository.
+//
+// If ref is a branch or tag (or [RefLatestTag]), the name of the branch or tag is returned, and whether it is a
+// tag. If ref is a commit SHA, an empty name is returned.
+func resolveRemoteRef(gitPath string, env []string, source, ref string) (string, bool, error) {
+	if ref == RefLatestTag {
+		refs, err := lsRemote(gitPath, env, source, []string{"--tags", "--sort=-version:refname"})
+		if err != nil {
+			return "", false, err
+		}
+
+		if len(refs) == 0 {
+			return "", false, fmt.Errorf("ref '%s': the repository does not have any tags", ref)
+		}
+
+		return strings.TrimPrefix(refs[0].name, "refs/tags/"), true, nil
+	}
+
+	refs, err := lsRemote(gitPath, env, source, []string{"--heads", "--tags"}, ref)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, r := range refs {
+		switch r.name {
+		case "refs/heads/" + ref:
+			return ref, false, nil
+		case "refs/tags/" + ref:
+			return ref, true, nil
+		}
+	}
+
+	if shaPattern.MatchString(ref) {
+		return "", false, nil
+	}
+
+	return "", false, fmt.Errorf("ref '%s' is not a branch, tag or commit SHA in the repository", ref)
+}
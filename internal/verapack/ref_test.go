@@ -0,0 +1,84 @@
+package verapack
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloneRepositoryRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	source := newTestRepository(t, "a.txt")
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", source, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	git("tag", "v1.10.0")
+	first := git("rev-parse", "HEAD")
+	git("commit", "--allow-empty", "-m", "second")
+	git("tag", "-a", "v1.9.0", "-m", "older version, newer commit")
+	second := git("rev-parse", "HEAD")
+	git("commit", "--allow-empty", "-m", "third")
+	third := git("rev-parse", "HEAD")
+
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantSHA string
+		wantTag string
+		wantErr bool
+	}{
+		{name: "branch", ref: "main", wantSHA: third},
+		{name: "tag", ref: "v1.9.0", wantSHA: second, wantTag: "v1.9.0"},
+		{name: "latest tag", ref: RefLatestTag, wantSHA: first, wantTag: "v1.10.0"},
+		{name: "full sha", ref: second, wantSHA: second},
+		{name: "short sha", ref: first[:8], wantSHA: first},
+		{name: "unknown ref", ref: "does-not-exist", wantErr: true},
+	}
+	for _, tt := range tests {
+		for _, cache := range []*bool{&disabled, &enabled} {
+			t.Run(fmt.Sprintf("%s/cache=%t", tt.name, *cache), func(t *testing.T) {
+				out := filepath.Join(t.TempDir(), "source")
+				options := Options{PackageSource: source, Type: Repo, Ref: tt.ref, CloneCache: cache}
+
+				info, log, err := CloneRepository(options, out, nil)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("CloneRepository() error = %v, wantErr %v\n%s", err, tt.wantErr, log)
+				}
+
+				if tt.wantErr {
+					return
+				}
+
+				if info.SHA != tt.wantSHA || info.Tag != tt.wantTag {
+					t.Errorf("CloneRepository() = %+v, want SHA %s and tag %s", info, tt.wantSHA, tt.wantTag)
+				}
+
+				if !strings.Contains(log, tt.wantSHA) {
+					t.Errorf("CloneRepository() log does not contain the resolved SHA:\n%s", log)
+				}
+
+				if _, err := os.Stat(filepath.Join(out, "a.txt")); err != nil {
+					t.Errorf("CloneRepository() did not check out the files: %v", err)
+				}
+			})
+		}
+	}
+}
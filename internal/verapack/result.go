@@ -11,6 +11,8 @@ import (
 	"github.com/DanCreative/veracode-go/veracode"
 )
 
+var errApplicationNotFound = errors.New("could not find an application")
+
 type result struct {
 	// PassedPolicy indicates whether the scan passed the SAST & SCA policy rules set for the application.
 	// It does not include scan frequency- or scan type rules, and it does not take into account grace periods.
@@ -22,6 +24,15 @@ type result struct {
 	PolicyStatus string
 }
 
+// hookResult returns the value of the result in the environment of the hooks, see [hookEnv].
+func (r result) hookResult() string {
+	if r.PassedPolicy {
+		return "pass"
+	}
+
+	return "fail"
+}
+
 // WaitForResult blocks the goroutine and periodically polls the API to check whether the scan has completed. Once it has, it returns whether the
 // build meets certain policy rules or not.
 func WaitForResult(ctx context.Context, client *veracode.Client, options Options, r reporter) (result, string, error) {
@@ -108,7 +119,7 @@ func getApplicationIdentifiers(ctx context.Context, client *veracode.Client, nam
 	if len(appList) == 0 {
 		// This should be impossible because the previous step in the process
 		// should catch it. I am placing a check here just to be safe.
-		return 0, "", fmt.Errorf("could not find an application with name: '%s'", name)
+		return 0, "", fmt.Errorf("%w with name: '%s'", errApplicationNotFound, name)
 	}
 
 	for _, app := range appList {
@@ -120,7 +131,7 @@ func getApplicationIdentifiers(ctx context.Context, client *veracode.Client, nam
 	}
 
 	// Again, this should be impossible at this point, but I am placing a check regardless.
-	return 0, "", fmt.Errorf("could not find an application with name: '%s'", name)
+	return 0, "", fmt.Errorf("%w with name: '%s'", errApplicationNotFound, name)
 }
 
 func getLatestBuild(ctx context.Context, client *veracode.Client, options Options) (id int, policyUpdateDate time.Time, err error) {
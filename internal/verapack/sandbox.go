@@ -60,17 +60,26 @@ func promoteSandbox(client *veracode.Client, ctx context.Context, app Options, a
 // appsToSandboxOptions creates new sandbox.SandboxOptions for the provided application that have ScanType ScanTypeSandbox or ScanTypePromote.
 //
 // Certain SandboxOptions fields are pointers that will directly mutate the original config values.
-func appsToSandboxOptions(applications []Options) []sand.SandboxOptions {
+//
+// Each application will use the client for its credentials profile.
+func appsToSandboxOptions(applications []Options, clients Clients) []sand.SandboxOptions {
 	r := make([]sand.SandboxOptions, 0, len(applications))
 	for k := range applications {
 		if applications[k].ScanType != ScanTypePolicy {
-			r = append(r, sand.SandboxOptions{
+			options := sand.SandboxOptions{
 				AppName:     applications[k].AppName,
 				AppGuid:     &applications[k].AppGuid,
 				SandboxName: applications[k].SandboxName,
 				SandboxId:   &applications[k].SandboxId,
 				SandboxGuid: &applications[k].SandboxGuid,
-			})
+			}
+
+			// If there is no client for the application's profile, the sandbox model's client is used.
+			if client := clients.For(applications[k]); client != nil {
+				options.Client = client.Client
+			}
+
+			r = append(r, options)
 		}
 	}
 
@@ -0,0 +1,419 @@
+package verapack
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// schemaFileName is the name of the JSON Schema file that is written next to the config file.
+	schemaFileName = "config.schema.json"
+	// optionsDefinition is the name of the definition of the [Options] in the JSON Schema.
+	optionsDefinition = "options"
+)
+
+var (
+	// configHelpText contains the descriptions of the settings of the config file that are not [Options].
+	// The descriptions of the options are in helpText.
+	configHelpText = map[string]string{
+		"config_version":                 "Version of the config file. Older config files are migrated with verapack config migrate.",
+		"default":                        "Default values that apply to all of the applications.",
+		"applications":                   "Applications that are packaged and scanned.",
+		"mirrors":                        "Download mirrors for the Veracode tools.",
+		"mirrors.wrapper":                "Base URL of a Maven repository that proxies Maven Central.",
+		"mirrors.packager":               "Base URL that proxies https://tools.veracode.com/veracode-cli.",
+		"auto_refresh_credentials":       "Automatically re-generate the API credentials before scanning, if they are about to expire.",
+		"credential_expiry_warning_days": "Number of days before the API credentials expire, in which the user is warned when scanning. 0 turns the warning off.",
+		"cache":                          "Limits of the clone cache.",
+		"cache.max_size":                 "Maximum size of the clone cache in MB.",
+		"cache.max_age":                  "Number of days after which an unused repository is removed from the clone cache.",
+		"credentials_store":              "Where the API credentials are stored. Values are file or secret_store.",
+	}
+
+	// inheritedRequiredFields are the required options that have a default value, see [setDynamicDefaults].
+	// They are not required by the JSON Schema, because the applications inherit them.
+	inheritedRequiredFields = []string{"version"}
+)
+
+// jsonSchema is a JSON Schema (draft-07) of a value in the config file.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	ExclusiveMinimum     *int                   `json:"exclusiveMinimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false, or the schema of the values of a map.
+	Required             []string               `json:"required,omitempty"`
+	Dependencies         map[string][]string    `json:"dependencies,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	AllOf                []*jsonSchema          `json:"allOf,omitempty"`
+	Not                  *jsonSchema            `json:"not,omitempty"`
+	Definitions          map[string]*jsonSchema `json:"definitions,omitempty"`
+}
+
+// GenerateSchema returns the JSON Schema of the config file. It is generated from the yaml and validate tags of
+// [Config] and [Options], so that editors can autocomplete and validate the config file. The validation rules
+// that depend on the file system or the templates are only checked by [ReadConfig].
+func GenerateSchema() ([]byte, error) {
+	options, appConstraints, err := structSchema(reflect.TypeOf(Options{}), "")
+	if err != nil {
+		return nil, err
+	}
+
+	root, _, err := structSchema(reflect.TypeOf(Config{}), "")
+	if err != nil {
+		return nil, err
+	}
+
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.Title = "verapack config"
+	root.Definitions = map[string]*jsonSchema{optionsDefinition: options}
+
+	// The default options are not required, because they are merged into the applications, which
+	// are validated with the required options.
+	root.Properties["default"] = &jsonSchema{Ref: "#/definitions/" + optionsDefinition, Description: configHelpText["default"]}
+	root.Properties["applications"].Items = &jsonSchema{
+		AllOf: append([]*jsonSchema{{Ref: "#/definitions/" + optionsDefinition}}, appConstraints...),
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// structSchema returns the schema of the struct, where path is the path of the struct in the config file. The
+// required options are returned as separate constraints, because they only apply to the applications. An error
+// is returned if the struct has a field of a kind that the schema does not support.
+func structSchema(t reflect.Type, path string) (*jsonSchema, []*jsonSchema, error) {
+	isOptions := t == reflect.TypeOf(Options{})
+
+	s := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema), AdditionalProperties: false}
+
+	var required []string
+	var anyOf []*jsonSchema
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		name, ok := yamlName(f)
+		if !ok {
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		rules, itemRules := splitValidateTag(f.Tag.Get("validate"))
+
+		if f.Type == reflect.TypeOf(Options{}) {
+			// The default options and applications are set by GenerateSchema.
+			s.Properties[name] = &jsonSchema{Ref: "#/definitions/" + optionsDefinition}
+			continue
+		}
+
+		p, err := fieldSchema(f.Type, fieldPath, rules, itemRules)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		p.Description = helpText[fieldPath]
+		if p.Description == "" {
+			p.Description = configHelpText[fieldPath]
+		}
+
+		for _, rule := range rules {
+			tag, param, _ := strings.Cut(rule, "=")
+
+			switch tag {
+			case "required":
+				if !isOptions || !slices.Contains(inheritedRequiredFields, name) {
+					required = append(required, name)
+				}
+			case "required_without":
+				// Fields that require each other are only added once.
+				other := yamlNameOf(t, param)
+				if !slices.ContainsFunc(anyOf, func(c *jsonSchema) bool { return c.AnyOf[0].Required[0] == other }) {
+					anyOf = append(anyOf, &jsonSchema{AnyOf: []*jsonSchema{{Required: []string{name}}, {Required: []string{other}}}})
+				}
+			case "excluded_with":
+				for _, other := range strings.Fields(param) {
+					s.AllOf = append(s.AllOf, &jsonSchema{Not: &jsonSchema{Required: []string{name, yamlNameOf(t, other)}}})
+				}
+			case "excluded_without":
+				if s.Dependencies == nil {
+					s.Dependencies = make(map[string][]string)
+				}
+
+				s.Dependencies[name] = append(s.Dependencies[name], yamlNameOf(t, param))
+			}
+		}
+
+		s.Properties[name] = p
+	}
+
+	if isOptions {
+		var constraints []*jsonSchema
+		if len(required) > 0 {
+			constraints = append(constraints, &jsonSchema{Required: required})
+		}
+
+		return s, append(constraints, anyOf...), nil
+	}
+
+	s.Required = required
+	s.AllOf = append(s.AllOf, anyOf...)
+
+	return s, nil, nil
+}
+
+// fieldSchema returns the schema of a field with the validation rules of the field and of its items.
+func fieldSchema(t reflect.Type, path string, rules, itemRules []string) (*jsonSchema, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var s *jsonSchema
+	var err error
+
+	switch t.Kind() {
+	case reflect.String:
+		s = &jsonSchema{Type: "string"}
+	case reflect.Int:
+		s = &jsonSchema{Type: "integer"}
+	case reflect.Bool:
+		s = &jsonSchema{Type: "boolean"}
+	case reflect.Slice:
+		s = &jsonSchema{Type: "array"}
+		s.Items, err = fieldSchema(t.Elem(), path, itemRules, nil)
+	case reflect.Map:
+		var values *jsonSchema
+		values, err = fieldSchema(t.Elem(), path, itemRules, nil)
+		s = &jsonSchema{Type: "object", AdditionalProperties: values}
+	case reflect.Struct:
+		s, _, err = structSchema(t, path)
+	default:
+		return nil, fmt.Errorf("%s: kind %s is not supported by the schema", path, t.Kind())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		tag, param, _ := strings.Cut(rule, "=")
+		n, _ := strconv.Atoi(param)
+
+		switch tag {
+		case "required":
+			if t.Kind() == reflect.String {
+				one := 1
+				s.MinLength = &one
+			}
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "lifecycle_stage":
+			s.Enum = lifecycleStages
+		case "url":
+			s.Format = "uri"
+		case "excludesall":
+			s.Pattern = fmt.Sprintf("^[^%s]*$", strings.ReplaceAll(param, "0x2C", ","))
+		case "gte", "min":
+			if t.Kind() == reflect.Int {
+				s.Minimum = &n
+			}
+		case "lte", "max":
+			if t.Kind() == reflect.Int {
+				s.Maximum = &n
+			}
+		case "gt":
+			switch t.Kind() {
+			case reflect.Int:
+				s.ExclusiveMinimum = &n
+			case reflect.Slice:
+				n++
+				s.MinItems = &n
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// splitValidateTag returns the rules of the field and of its items or map values from a validate tag.
+func splitValidateTag(tag string) ([]string, []string) {
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+
+	rules, itemTag, _ := strings.Cut(tag, ",dive")
+	itemTag = strings.TrimPrefix(itemTag, ",")
+
+	// Skip the rules of the map keys.
+	if _, after, ok := strings.Cut(itemTag, "endkeys"); ok {
+		itemTag = strings.TrimPrefix(after, ",")
+	}
+
+	var itemRules []string
+	if itemTag != "" {
+		itemRules = strings.Split(itemTag, ",")
+	}
+
+	return strings.Split(rules, ","), itemRules
+}
+
+// yamlName returns the name of the field in the config file, and whether the field is in the config file.
+func yamlName(f reflect.StructField) (string, bool) {
+	tag, ok := f.Tag.Lookup("yaml")
+	if !ok || tag == "-" {
+		return "", false
+	}
+
+	return strings.Split(tag, ",")[0], true
+}
+
+// yamlNameOf returns the name in the config file of the field of the struct.
+func yamlNameOf(t reflect.Type, field string) string {
+	if f, ok := t.FieldByName(field); ok {
+		if name, ok := yamlName(f); ok {
+			return name
+		}
+	}
+
+	return field
+}
+
+// UnknownKeyError is the error of a key in the config file that is not a setting.
+type UnknownKeyError struct {
+	Path       string // Path of the map that contains the key, e.g. applications[0].hooks.
+	Key        string
+	Suggestion string // Name of the setting that is most similar to the key, if any.
+}
+
+func (e *UnknownKeyError) Error() string {
+	msg := fmt.Sprintf("config validation error at %s: unknown field '%s'", e.Path, e.Key)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean '%s'?", e.Suggestion)
+	}
+
+	return msg
+}
+
+// checkUnknownKeys returns an [UnknownKeyError] for every key in the config file that is not a setting, because
+// yaml.Unmarshal silently ignores them. Values of the wrong type are reported by yaml.Unmarshal instead.
+func checkUnknownKeys(content []byte) []error {
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(content, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil
+	}
+
+	return unknownKeys(doc, reflect.TypeOf(Config{}), "config")
+}
+
+// unknownKeys returns the unknown keys of the value of the config file, that is decoded into t.
+func unknownKeys(value any, t reflect.Type, path string) []error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var errs []error
+
+	switch t.Kind() {
+	case reflect.Slice:
+		items, _ := value.([]any)
+		for k, item := range items {
+			errs = append(errs, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, k))...)
+		}
+
+	case reflect.Struct:
+		doc, ok := value.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+
+		fields := make(map[string]reflect.Type)
+		names := make([]string, 0, t.NumField())
+
+		for i := range t.NumField() {
+			if name, ok := yamlName(t.Field(i)); ok {
+				fields[name] = t.Field(i).Type
+				names = append(names, name)
+			}
+		}
+
+		for _, item := range doc {
+			key := fmt.Sprint(item.Key)
+			if key == "<<" {
+				// YAML merge keys are resolved by yaml.Unmarshal.
+				continue
+			}
+
+			ft, ok := fields[key]
+			if !ok {
+				errs = append(errs, &UnknownKeyError{Path: path, Key: key, Suggestion: suggestKey(key, names)})
+				continue
+			}
+
+			errs = append(errs, unknownKeys(item.Value, ft, path+"."+key)...)
+		}
+	}
+
+	return errs
+}
+
+// suggestKey returns the name that is most similar to the key, or an empty string if none of the names are similar.
+func suggestKey(key string, names []string) string {
+	var suggestion string
+	best := max(2, len(key)/3) + 1
+
+	for _, name := range names {
+		if d := levenshtein(strings.ToLower(key), name); d < best {
+			suggestion, best = name, d
+		}
+	}
+
+	return suggestion
+}
+
+// levenshtein returns the number of single character edits that are needed to change a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,102 @@
+package verapack
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	b, err := GenerateSchema()
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+
+	var schema jsonSchema
+	if err = json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("GenerateSchema() is not valid JSON: %v", err)
+	}
+
+	options := schema.Definitions[optionsDefinition]
+	if options == nil {
+		t.Fatal("GenerateSchema() does not define the options")
+	}
+
+	if got := options.Properties["type"].Enum; !slices.Equal(got, []string{string(Directory), string(Repo)}) {
+		t.Errorf("type enum = %v, want [directory repo]", got)
+	}
+
+	if options.AdditionalProperties != false {
+		t.Errorf("options additionalProperties = %v, want false", options.AdditionalProperties)
+	}
+
+	if got := options.Properties["hooks"].Properties["timeout"].Minimum; got == nil || *got != 0 {
+		t.Errorf("hooks.timeout minimum = %v, want 0", got)
+	}
+
+	items := schema.Properties["applications"].Items
+	if items == nil || len(items.AllOf) != 3 {
+		t.Fatalf("applications items = %+v, want the options with the required app_name and package_source or artefact_paths", items)
+	}
+
+	if got := items.AllOf[1].Required; !slices.Equal(got, []string{"app_name"}) {
+		t.Errorf("applications required = %v, want [app_name]", got)
+	}
+
+	if got := items.AllOf[2].AnyOf; len(got) != 2 || got[0].Required[0] != "artefact_paths" || got[1].Required[0] != "package_source" {
+		t.Errorf("applications anyOf = %+v, want artefact_paths or package_source", got)
+	}
+}
+
+func TestCheckUnknownKeys(t *testing.T) {
+	if errs := checkUnknownKeys(configFileBytes); len(errs) > 0 {
+		t.Errorf("checkUnknownKeys() of the config template = %v, want no errors", errs)
+	}
+
+	content := `default:
+  verbsoe: true
+applications:
+  - app_name: Example
+    pacakge_source: C:\app\source
+    hooks:
+      pre_pakage: make
+      custom: value
+mirrors:
+  wrapper:
+    url: https://example.com
+`
+
+	errs := checkUnknownKeys([]byte(content))
+
+	want := []UnknownKeyError{
+		{Path: "config.default", Key: "verbsoe", Suggestion: "verbose"},
+		{Path: "config.applications[0]", Key: "pacakge_source", Suggestion: "package_source"},
+		{Path: "config.applications[0].hooks", Key: "pre_pakage", Suggestion: "pre_package"},
+		{Path: "config.applications[0].hooks", Key: "custom"},
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("checkUnknownKeys() = %v, want %d errors", errs, len(want))
+	}
+
+	for k, err := range errs {
+		var got *UnknownKeyError
+		if !errors.As(err, &got) || *got != want[k] {
+			t.Errorf("checkUnknownKeys()[%d] = %v, want %v", k, err, &want[k])
+		}
+	}
+}
+
+func TestStructSchemaUnsupportedKind(t *testing.T) {
+	type options struct {
+		Name  string             `yaml:"name"`
+		Ratio map[string]float64 `yaml:"ratio"`
+	}
+
+	if _, _, err := structSchema(reflect.TypeOf(options{}), "build"); err == nil || !strings.Contains(err.Error(), "build.ratio") {
+		t.Errorf("structSchema() error = %v, want an error for the float field", err)
+	}
+}
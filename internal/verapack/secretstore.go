@@ -0,0 +1,236 @@
+package verapack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// CredentialsStoreFile stores the credentials in the plaintext credential files that are shared with the other Veracode tools.
+	CredentialsStoreFile = "file"
+	// CredentialsStoreSecret stores the credentials in the OS secret service, or in an encrypted file if the secret service is not available.
+	CredentialsStoreSecret = "secret_store"
+)
+
+var (
+	// errSecretServiceUnavailable is returned by the secret service functions if the OS does not provide a secret
+	// service that verapack can use. The encrypted file is used instead.
+	errSecretServiceUnavailable = errors.New("the secret service is not available")
+	// errSecretNotFound is returned by the secret service functions if there are no credentials stored for a profile.
+	errSecretNotFound = errors.New("secret not found")
+)
+
+// storedCredentials is how a single profile's credentials are stored in the secret service and the encrypted file.
+type storedCredentials struct {
+	ApiKeyId     string `json:"api_key_id"`
+	ApiKeySecret string `json:"api_key_secret"`
+}
+
+// credentialsStore is a backend that the API credentials are read from and written to.
+type credentialsStore interface {
+	// get returns the credentials of profile. If profile is empty, the VERACODE_API_PROFILE environment
+	// variable or the default profile is used.
+	get(profile string) (string, string, error)
+	// set stores the credentials of profile. If profile is empty, the default profile is used.
+	set(profile, apiKey, apiSecret string) error
+}
+
+// LoadCredentialsStore returns the credentials store that is selected with the VERAPACK_CREDENTIALS_STORE environment
+// variable, or with the credentials_store field in the config file. The plaintext files are used by default.
+//
+// Like [LoadMirrors], only the one field is read from the config file, because the credentials commands must work
+// before the user has added their applications. A missing config file is not an error.
+func LoadCredentialsStore(homeDir string) (credentialsStore, error) {
+	var c struct {
+		CredentialsStore string `yaml:"credentials_store"`
+	}
+
+	content, err := os.ReadFile(filepath.Join(homeDir, ".veracode", "verapack", "config.yaml"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	if v, ok := os.LookupEnv("VERAPACK_CREDENTIALS_STORE"); ok {
+		c.CredentialsStore = v
+	}
+
+	switch c.CredentialsStore {
+	case "", CredentialsStoreFile:
+		return fileCredentialsStore{homeDir: homeDir}, nil
+	case CredentialsStoreSecret:
+		return secretCredentialsStore{homeDir: homeDir}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials store: '%s', must be one of: [%s %s]", c.CredentialsStore, CredentialsStoreFile, CredentialsStoreSecret)
+	}
+}
+
+// storeCredentials sets the credentials of profile in the selected credentials store.
+func storeCredentials(homeDir, profile, apiKey, apiSecret string) error {
+	store, err := LoadCredentialsStore(homeDir)
+	if err != nil {
+		return err
+	}
+
+	return store.set(profile, apiKey, apiSecret)
+}
+
+// fileCredentialsStore reads and writes the plaintext credential files in %home%/.veracode.
+type fileCredentialsStore struct {
+	homeDir string
+}
+
+func (s fileCredentialsStore) get(profile string) (string, string, error) {
+	if profile == "" {
+		return veracode.LoadVeracodeCredentials()
+	}
+
+	filePath := filepath.Join(s.homeDir, ".veracode", "credentials")
+
+	profiles, err := veracode.GetProfiles(filePath)
+	if err != nil {
+		return "", "", err
+	}
+
+	p, ok := profiles[profile]
+	if !ok {
+		return "", "", fmt.Errorf("credentials profile '%s' could not be found in %s, please add it by running: %s", profile, filePath, lightBlueForeground.Render("verapack credentials configure --profile "+profile))
+	}
+
+	return p.VeracodeApiKeyId, p.VeracodeApiKeySecret, nil
+}
+
+func (s fileCredentialsStore) set(profile, apiKey, apiSecret string) error {
+	return setCredentialsFiles(s.homeDir, profile, apiKey, apiSecret)
+}
+
+// secretCredentialsStore stores the credentials in the OS secret service. If the secret service is not available,
+// the credentials are stored in an encrypted file instead: %home%/.veracode/verapack/credentials.enc
+//
+// The Veracode tools can not read the credentials from here, therefore verapack passes them to the tools with
+// environment variables. See [ProfileClient].Env.
+type secretCredentialsStore struct {
+	homeDir string
+}
+
+func (s secretCredentialsStore) get(profile string) (string, string, error) {
+	profile = resolveProfileName(profile)
+
+	creds, err := secretServiceGet(profile)
+	if errors.Is(err, errSecretServiceUnavailable) {
+		creds, err = s.getEncrypted(profile)
+	}
+
+	if errors.Is(err, errSecretNotFound) {
+		return "", "", fmt.Errorf("credentials profile '%s' could not be found in the secret store, please add it by running: %s", profile, lightBlueForeground.Render("verapack credentials configure --profile "+profile))
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return creds.ApiKeyId, creds.ApiKeySecret, nil
+}
+
+func (s secretCredentialsStore) set(profile, apiKey, apiSecret string) error {
+	profile = resolveProfileName(profile)
+	creds := storedCredentials{ApiKeyId: apiKey, ApiKeySecret: apiSecret}
+
+	err := secretServiceSet(profile, creds)
+	if errors.Is(err, errSecretServiceUnavailable) {
+		return s.setEncrypted(profile, creds)
+	}
+
+	return err
+}
+
+func (s secretCredentialsStore) encryptedFilePath() string {
+	return filepath.Join(s.homeDir, ".veracode", "verapack", "credentials.enc")
+}
+
+// readEncrypted decrypts and returns all of the profiles in the encrypted file. A missing file is not an error.
+func (s secretCredentialsStore) readEncrypted() (map[string]storedCredentials, error) {
+	profiles := make(map[string]storedCredentials)
+
+	content, err := os.ReadFile(s.encryptedFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return profiles, nil
+		}
+		return nil, err
+	}
+
+	plaintext, err := unprotectData(s.homeDir, content)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s: %w", s.encryptedFilePath(), err)
+	}
+
+	if err = json.Unmarshal(plaintext, &profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (s secretCredentialsStore) getEncrypted(profile string) (storedCredentials, error) {
+	profiles, err := s.readEncrypted()
+	if err != nil {
+		return storedCredentials{}, err
+	}
+
+	creds, ok := profiles[profile]
+	if !ok {
+		return storedCredentials{}, errSecretNotFound
+	}
+
+	return creds, nil
+}
+
+func (s secretCredentialsStore) setEncrypted(profile string, creds storedCredentials) error {
+	profiles, err := s.readEncrypted()
+	if err != nil {
+		return err
+	}
+
+	profiles[profile] = creds
+
+	plaintext, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := protectData(s.homeDir, plaintext)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.encryptedFilePath()), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.encryptedFilePath(), ciphertext, 0600)
+}
+
+// resolveProfileName returns the name of the profile that will be used if profile is empty, the same way as
+// [veracode.LoadVeracodeCredentials] selects it.
+func resolveProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+
+	if profile = os.Getenv("VERACODE_API_PROFILE"); profile != "" {
+		return profile
+	}
+
+	return defaultCredentialsProfile
+}
@@ -0,0 +1,86 @@
+//go:build !windows
+
+package verapack
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"os"
+)
+
+const (
+	// passphraseEnv is the environment variable with the passphrase that the encrypted credentials file is
+	// encrypted with, on platforms without DPAPI.
+	passphraseEnv = "VERAPACK_CREDENTIALS_PASSPHRASE"
+	// keyIterations is the number of PBKDF2 iterations that derive the key from the passphrase.
+	keyIterations = 600000
+	saltSize      = 16
+)
+
+// errPassphraseNotSet is returned if the encrypted credentials file is used, but its passphrase is not set.
+var errPassphraseNotSet = errors.New("the Secret Service is not available, and the " + passphraseEnv + " environment variable, which the credentials file is encrypted with, is not set")
+
+// newGCM derives the key from the passphrase and the salt, and returns the cipher. The key is never stored, so that
+// the encrypted file can not be decrypted with the other files in the user's home directory.
+func newGCM(salt []byte) (cipher.AEAD, error) {
+	passphrase := os.Getenv(passphraseEnv)
+	if passphrase == "" {
+		return nil, errPassphraseNotSet
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// protectData encrypts data with AES-GCM, with a key that is derived from the passphrase. The salt and the nonce are
+// prepended to the ciphertext.
+func protectData(homeDir string, data []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(append(salt, nonce...), nonce, data, nil), nil
+}
+
+// unprotectData decrypts data that was encrypted with [protectData].
+func unprotectData(homeDir string, data []byte) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	gcm, err := newGCM(data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
@@ -0,0 +1,63 @@
+package verapack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+)
+
+// secretServiceGet reads the credentials of profile from the Secret Service, using the secret-tool
+// command of libsecret.
+func secretServiceGet(profile string) (storedCredentials, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return storedCredentials{}, errSecretServiceUnavailable
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(path, "lookup", "service", "verapack", "profile", profile)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err = cmd.Run(); err != nil {
+		// secret-tool exits with 1 and no output if the secret does not exist. Any other error means
+		// that the Secret Service could not be reached, e.g. when there is no D-Bus session.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			return storedCredentials{}, errSecretNotFound
+		}
+
+		return storedCredentials{}, errSecretServiceUnavailable
+	}
+
+	var creds storedCredentials
+	if err = json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return storedCredentials{}, err
+	}
+
+	return creds, nil
+}
+
+// secretServiceSet stores the credentials of profile in the Secret Service, using the secret-tool
+// command of libsecret.
+func secretServiceSet(profile string, creds storedCredentials) error {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return errSecretServiceUnavailable
+	}
+
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(path, "store", "--label=Verapack API credentials ("+profile+")", "service", "verapack", "profile", profile)
+	cmd.Stdin = bytes.NewReader(secret)
+
+	if err = cmd.Run(); err != nil {
+		return errSecretServiceUnavailable
+	}
+
+	return nil
+}
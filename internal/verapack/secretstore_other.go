@@ -0,0 +1,13 @@
+//go:build !windows && !linux
+
+package verapack
+
+// secretServiceGet is not supported on this platform, the encrypted file is used instead.
+func secretServiceGet(profile string) (storedCredentials, error) {
+	return storedCredentials{}, errSecretServiceUnavailable
+}
+
+// secretServiceSet is not supported on this platform, the encrypted file is used instead.
+func secretServiceSet(profile string, creds storedCredentials) error {
+	return errSecretServiceUnavailable
+}
@@ -0,0 +1,72 @@
+package verapack
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSecretCredentialsStoreEncryptedFallback(t *testing.T) {
+	// Hide secret-tool, so that the encrypted file is used.
+	t.Setenv("PATH", "")
+	t.Setenv("VERACODE_API_PROFILE", "")
+	t.Setenv("VERAPACK_CREDENTIALS_STORE", CredentialsStoreSecret)
+	// The passphrase of the encrypted file on platforms without DPAPI.
+	t.Setenv("VERAPACK_CREDENTIALS_PASSPHRASE", "correct horse battery staple")
+
+	homeDir := t.TempDir()
+
+	store, err := LoadCredentialsStore(homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.set("", "default-id", "default-secret"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if err = store.set("eu", "vera01ei-id", "eu-secret"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(homeDir, ".veracode", "verapack", "credentials.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(content), "secret") {
+		t.Errorf("credentials file contains plaintext credentials")
+	}
+
+	key, secret, err := store.get("")
+	if err != nil || key != "default-id" || secret != "default-secret" {
+		t.Errorf("get(default) = %s, %s, %v", key, secret, err)
+	}
+
+	key, secret, err = store.get("eu")
+	if err != nil || key != "vera01ei-id" || secret != "eu-secret" {
+		t.Errorf("get(eu) = %s, %s, %v", key, secret, err)
+	}
+
+	if _, _, err = store.get("missing"); err == nil {
+		t.Errorf("get(missing) expected an error")
+	}
+
+	if runtime.GOOS != "windows" {
+		if _, err = os.Stat(filepath.Join(homeDir, ".veracode", "verapack", "credentials.key")); err == nil {
+			t.Errorf("the encryption key was stored next to the credentials file")
+		}
+
+		t.Setenv("VERAPACK_CREDENTIALS_PASSPHRASE", "")
+		if _, _, err = store.get("eu"); err == nil {
+			t.Errorf("get(eu) without the passphrase expected an error")
+		}
+
+		t.Setenv("VERAPACK_CREDENTIALS_PASSPHRASE", "wrong")
+		if _, _, err = store.get("eu"); err == nil {
+			t.Errorf("get(eu) with the wrong passphrase expected an error")
+		}
+	}
+}
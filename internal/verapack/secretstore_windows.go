@@ -0,0 +1,58 @@
+package verapack
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// secretServiceGet is not supported on Windows. The encrypted file is protected with DPAPI instead, which ties
+// it to the current Windows user account.
+func secretServiceGet(profile string) (storedCredentials, error) {
+	return storedCredentials{}, errSecretServiceUnavailable
+}
+
+// secretServiceSet is not supported on Windows. See [secretServiceGet].
+func secretServiceSet(profile string, creds storedCredentials) error {
+	return errSecretServiceUnavailable
+}
+
+// protectData encrypts data with DPAPI for the current user.
+func protectData(homeDir string, data []byte) ([]byte, error) {
+	var out windows.DataBlob
+
+	if err := windows.CryptProtectData(newDataBlob(data), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+
+	return dataBlobBytes(out), nil
+}
+
+// unprotectData decrypts data that was encrypted with [protectData].
+func unprotectData(homeDir string, data []byte) ([]byte, error) {
+	var out windows.DataBlob
+
+	if err := windows.CryptUnprotectData(newDataBlob(data), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+
+	return dataBlobBytes(out), nil
+}
+
+func newDataBlob(data []byte) *windows.DataBlob {
+	if len(data) == 0 {
+		return &windows.DataBlob{}
+	}
+
+	return &windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+}
+
+// dataBlobBytes copies the content of a DataBlob allocated by Windows and frees it.
+func dataBlobBytes(blob windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(blob.Data)))
+
+	r := make([]byte, blob.Size)
+	copy(r, unsafe.Slice(blob.Data, blob.Size))
+
+	return r
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"net/http"
 	"net/http/cookiejar"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -311,15 +310,25 @@ func SetupConfig(homeDir, appDir string) multistagesetup.SetupTask {
 			return func() tea.Msg {
 				var err error
 
-				_, err = os.Stat(filepath.Join(appDir, "config.yaml"))
-				if err == nil {
-					return multistagesetup.NewSkippedTaskResult("already setup", nil)
+				if err = os.MkdirAll(appDir, 0600); err != nil {
+					return multistagesetup.NewFailedTaskResult("", err, nil)
 				}
 
-				if err = os.MkdirAll(appDir, 0600); err != nil {
+				// The schema is always written, so that it is up to date with the installed version.
+				schema, err := GenerateSchema()
+				if err != nil {
+					return multistagesetup.NewFailedTaskResult("", err, nil)
+				}
+
+				if err = os.WriteFile(filepath.Join(appDir, schemaFileName), schema, 0600); err != nil {
 					return multistagesetup.NewFailedTaskResult("", err, nil)
 				}
 
+				_, err = os.Stat(filepath.Join(appDir, "config.yaml"))
+				if err == nil {
+					return multistagesetup.NewSkippedTaskResult("already setup", nil)
+				}
+
 				file, err := os.Create(filepath.Join(appDir, "config.yaml"))
 				if err != nil {
 					return multistagesetup.NewFailedTaskResult("", err, nil)
@@ -342,11 +351,23 @@ func SetupCredentialsUserPrompt(loadCredFunc func() (string, string, error)) mul
 	return multistagesetup.NewSetupTask("User generate and enter credentials", NewCredentialsTask(loadCredFunc))
 }
 
+// SetupCredentialsFile stores the credentials in the veracode.yml file, or in the secret store if the credentials_store
+// is secret_store.
 func SetupCredentialsFile(homeDir string) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Create credential file", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
-			var err error
-			if _, err = os.Stat(filepath.Join(homeDir, ".veracode", "veracode.yml")); err == nil {
+			store, err := LoadCredentialsStore(homeDir)
+			if err != nil {
+				return multistagesetup.NewFailedTaskResult("", err, nil)
+			}
+
+			_, isSecretStore := store.(secretCredentialsStore)
+
+			if isSecretStore {
+				if _, _, err = store.get(""); err == nil {
+					return multistagesetup.NewSkippedTaskResult("already setup in the secret store", nil)
+				}
+			} else if _, err = os.Stat(filepath.Join(homeDir, ".veracode", "veracode.yml")); err == nil {
 				return multistagesetup.NewSkippedTaskResult("already setup", nil)
 			}
 
@@ -357,6 +378,14 @@ func SetupCredentialsFile(homeDir string) multistagesetup.SetupTask {
 				return multistagesetup.NewFailedTaskResult("", errors.New("api key and/or secret not set"), nil)
 			}
 
+			if isSecretStore {
+				if err = store.set("", apiKey, apiSecret); err != nil {
+					return multistagesetup.NewFailedTaskResult("", err, nil)
+				}
+
+				return multistagesetup.NewSuccessfulTaskResult("stored in the secret store", nil)
+			}
+
 			err = setCredentialsFile(homeDir, apiKey, apiSecret)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
@@ -367,10 +396,20 @@ func SetupCredentialsFile(homeDir string) multistagesetup.SetupTask {
 	}))
 }
 
+// SetupCredentialsFileLegacy stores the credentials in the legacy credentials file. It is skipped if the
+// credentials_store is secret_store, see [SetupCredentialsFile].
 func SetupCredentialsFileLegacy(homeDir string) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Create legacy credential file", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
-			var err error
+			store, err := LoadCredentialsStore(homeDir)
+			if err != nil {
+				return multistagesetup.NewFailedTaskResult("", err, nil)
+			}
+
+			if _, ok := store.(secretCredentialsStore); ok {
+				return multistagesetup.NewSkippedTaskResult("the credentials are stored in the secret store", nil)
+			}
+
 			if _, err = os.Stat(filepath.Join(homeDir, ".veracode", "credentials")); err == nil {
 				return multistagesetup.NewSkippedTaskResult("already setup", nil)
 			}
@@ -382,7 +421,7 @@ func SetupCredentialsFileLegacy(homeDir string) multistagesetup.SetupTask {
 				return multistagesetup.NewFailedTaskResult("", errors.New("api key and/or secret not set"), nil)
 			}
 
-			err = setLegacyCredentialsFile(homeDir, apiKey, apiSecret)
+			err = setLegacyCredentialsFile(homeDir, defaultCredentialsProfile, apiKey, apiSecret)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
 			}
@@ -392,7 +431,7 @@ func SetupCredentialsFileLegacy(homeDir string) multistagesetup.SetupTask {
 	}))
 }
 
-func SetupInstallDependencyPackager() multistagesetup.SetupTask {
+func SetupInstallDependencyPackager(mirror Mirror) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Install Veracode CLI", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
 			packagerPath := getPackagerLocation()
@@ -404,7 +443,7 @@ func SetupInstallDependencyPackager() multistagesetup.SetupTask {
 				return multistagesetup.NewSkippedTaskResult("already installed version: "+localVersion, nil)
 			}
 
-			version, err := InstallPackager(false, packagerPath)
+			version, err := InstallPackager(false, packagerPath, mirror)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
 			}
@@ -414,7 +453,7 @@ func SetupInstallDependencyPackager() multistagesetup.SetupTask {
 	}))
 }
 
-func UpdateDependencyPackager() multistagesetup.SetupTask {
+func UpdateDependencyPackager(mirror Mirror) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Update Veracode CLI", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
 			packagerPath := getPackagerLocation()
@@ -424,15 +463,14 @@ func UpdateDependencyPackager() multistagesetup.SetupTask {
 			client := &http.Client{
 				Jar: jar,
 			}
-			baseURL, _ := url.Parse("https://tools.veracode.com/veracode-cli")
 
-			fileVersion, _ := GetLatestPackagerVersion(client, baseURL)
+			fileVersion, _ := GetLatestPackagerVersion(client, mirror)
 
 			if fileVersion == packagerCurrentVersion {
 				return multistagesetup.NewSkippedTaskResult("already on the latest version: "+fileVersion, nil)
 			}
 
-			version, err := InstallPackager(false, packagerPath)
+			version, err := InstallPackager(false, packagerPath, mirror)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
 			}
@@ -442,7 +480,7 @@ func UpdateDependencyPackager() multistagesetup.SetupTask {
 	}))
 }
 
-func SetupInstallDependencyWrapper() multistagesetup.SetupTask {
+func SetupInstallDependencyWrapper(mirror Mirror) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Install Veracode Uploader", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
 			wrapperPath := getWrapperLocation()
@@ -454,7 +492,7 @@ func SetupInstallDependencyWrapper() multistagesetup.SetupTask {
 				return multistagesetup.NewSkippedTaskResult("already installed version: "+localVersion, nil)
 			}
 
-			version, err := InstallUploader(wrapperPath)
+			version, err := InstallUploader(wrapperPath, mirror)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
 			}
@@ -464,7 +502,7 @@ func SetupInstallDependencyWrapper() multistagesetup.SetupTask {
 	}))
 }
 
-func UpdateDependencyWrapper() multistagesetup.SetupTask {
+func UpdateDependencyWrapper(mirror Mirror) multistagesetup.SetupTask {
 	return multistagesetup.NewSetupTask("Update Veracode Uploader", NewSimpleTask(func(values map[string]any) tea.Cmd {
 		return func() tea.Msg {
 			wrapperPath := getWrapperLocation()
@@ -475,13 +513,13 @@ func UpdateDependencyWrapper() multistagesetup.SetupTask {
 				Jar: jar,
 			}
 
-			latestVersion, _ := GetLatestUploaderVersion(client)
+			latestVersion, _ := GetLatestUploaderVersion(client, mirror)
 
 			if wrapperCurrentVersion == latestVersion {
 				return multistagesetup.NewSkippedTaskResult("already on the latest version: "+wrapperCurrentVersion, nil)
 			}
 
-			version, err := InstallUploader(wrapperPath)
+			version, err := InstallUploader(wrapperPath, mirror)
 			if err != nil {
 				return multistagesetup.NewFailedTaskResult("", err, nil)
 			}
@@ -512,12 +550,11 @@ func Prerequisites() multistagesetup.SetupTask {
 				warnings: make([]string, 0, 2),
 			}
 
-			_, err := exec.LookPath("java")
-			if err != nil {
-				p.warnings = append(p.warnings, "Java was not found on the path. You can either install Java version 8, 11 or 17.")
+			if r := checkJava(); r.Status != doctorStatusOk {
+				p.warnings = append(p.warnings, r.Message)
 			}
 
-			_, err = exec.LookPath("git")
+			_, err := exec.LookPath("git")
 			if err != nil {
 				p.warnings = append(p.warnings, "git was not found on the path.")
 			}
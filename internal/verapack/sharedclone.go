@@ -0,0 +1,122 @@
+package verapack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// sharedClone is a clone of a repository that is packaged by multiple applications, e.g. the applications
+// of a monorepo. The repository is cloned by the first application that needs it, and removed after the last
+// application has released it.
+type sharedClone struct {
+	options Options // Options of the first application, which contain the clone options of all of them.
+	cleanup bool    // Whether the clone is removed after it has been released by all of the applications.
+
+	once sync.Once
+	dir  string
+	git  GitInfo
+	out  string
+	err  error
+
+	mu      sync.Mutex
+	pending int
+}
+
+// sharedCloneKey returns the key that identifies the clone of an application. Applications with the same key
+// can share the clone. An empty key is returned if the application is not packaged from a clone, or if it runs
+// commands in the clone while it is packaged, i.e. a build command or package hooks, because the commands of the
+// applications would run concurrently in the same working tree and overwrite each other's build outputs.
+func sharedCloneKey(options Options) string {
+	if !hasPackageTask(options) || !(options.Type == Repo || options.Branch != "" || options.Ref != "") {
+		return ""
+	}
+
+	if options.Build != nil || options.Hooks.PrePackage != "" || options.Hooks.PostPackage != "" {
+		return ""
+	}
+
+	// Every option that changes the checkout is part of the key.
+	key, _ := json.Marshal(struct {
+		Source      string
+		Type        SourceType
+		Branch      string
+		Ref         string
+		Submodules  bool
+		LFS         bool
+		SparsePaths []string
+		GitAuth     *GitAuth
+	}{options.PackageSource, options.Type, options.Branch, options.Ref, options.Submodules, options.LFS, options.SparsePaths, options.GitAuth})
+
+	return string(key)
+}
+
+// groupSharedClones returns the shared clones of the applications, by the index of the application. Only
+// the applications that share their clone with at least one other application are included.
+func groupSharedClones(applications []Options) map[int]*sharedClone {
+	groups := make(map[string][]int)
+
+	for k, app := range applications {
+		if key := sharedCloneKey(app); key != "" {
+			groups[key] = append(groups[key], k)
+		}
+	}
+
+	r := make(map[int]*sharedClone)
+
+	for _, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		s := &sharedClone{options: applications[indexes[0]], cleanup: true, pending: len(indexes)}
+
+		for _, k := range indexes {
+			// The clone is kept if any of the applications want to keep their packaging files.
+			s.cleanup = s.cleanup && *applications[k].AutoCleanup
+			r[k] = s
+		}
+	}
+
+	return r
+}
+
+// clone returns the metadata of the cloned commit, the directory of the clone, the log output and any error.
+// Only the first call clones the repository, subsequent calls wait for it and return the same result.
+func (s *sharedClone) clone() (GitInfo, string, string, error) {
+	s.once.Do(func() {
+		workdir := filepath.Join(os.TempDir(), "verapack", "workdir")
+		if s.err = os.MkdirAll(workdir, 0700); s.err != nil {
+			s.out = s.err.Error()
+			return
+		}
+
+		if s.dir, s.err = os.MkdirTemp(workdir, "shared-clone-"); s.err != nil {
+			s.out = s.err.Error()
+			return
+		}
+
+		s.git, s.out, s.err = CloneRepository(s.options, filepath.Join(s.dir, "source"), nil)
+	})
+
+	return s.git, filepath.Join(s.dir, "source"), s.out, s.err
+}
+
+// release is called by an application once it does not need the clone anymore. The clone is removed after
+// all of the applications have released it.
+func (s *sharedClone) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending--
+
+	if s.pending > 0 || !s.cleanup || s.dir == "" {
+		return
+	}
+
+	if err := os.RemoveAll(s.dir); err != nil {
+		logToFile(fmt.Sprintf("could not remove the shared clone %s: %s", s.dir, err))
+	}
+}
@@ -0,0 +1,69 @@
+package verapack
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGroupSharedClones(t *testing.T) {
+	enabled, disabled := true, false
+
+	apps := []Options{
+		{AppName: "api", PackageSource: "https://example.com/mono.git", Type: Repo, Branch: "main", SourceSubdir: "api", AutoCleanup: &enabled},
+		{AppName: "web", PackageSource: "https://example.com/mono.git", Type: Repo, Branch: "main", SourceSubdir: "web", AutoCleanup: &disabled},
+		{AppName: "release", PackageSource: "https://example.com/mono.git", Type: Repo, Branch: "release", AutoCleanup: &enabled},
+		{AppName: "local", PackageSource: "/src/local", Type: Directory, AutoCleanup: &enabled},
+		{AppName: "build", PackageSource: "https://example.com/mono.git", Type: Repo, Branch: "main", Build: &BuildOptions{Command: "make"}, AutoCleanup: &enabled},
+		{AppName: "hook", PackageSource: "https://example.com/mono.git", Type: Repo, Branch: "main", Hooks: Hooks{PrePackage: "npm ci"}, AutoCleanup: &enabled},
+	}
+	for k := range apps {
+		apps[k].ScanType = ScanTypeSandbox
+	}
+
+	shared := groupSharedClones(apps)
+
+	if len(shared) != 2 || shared[0] == nil || shared[0] != shared[1] {
+		t.Fatalf("groupSharedClones() = %v, expected the main branch applications to share a clone", shared)
+	}
+
+	if shared[0].pending != 2 || shared[0].cleanup {
+		t.Errorf("groupSharedClones() pending = %d, cleanup = %v, want 2, false", shared[0].pending, shared[0].cleanup)
+	}
+}
+
+func TestSharedClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("TMPDIR", t.TempDir())
+
+	source := newTestRepository(t, "a.txt")
+	s := &sharedClone{options: Options{PackageSource: source, Type: Repo}, cleanup: true, pending: 2}
+
+	info, dir, log, err := s.clone()
+	if err != nil {
+		t.Fatalf("clone() error = %v\n%s", err, log)
+	}
+
+	// The second application gets the same clone.
+	if info2, dir2, _, _ := s.clone(); info2 != info || dir2 != dir {
+		t.Errorf("clone() cloned the repository twice: %s, %s", dir, dir2)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Errorf("clone() did not clone the file: %v", err)
+	}
+
+	s.release()
+	if _, err = os.Stat(dir); err != nil {
+		t.Errorf("release() removed the clone while it is still in use")
+	}
+
+	s.release()
+	if _, err = os.Stat(dir); err == nil {
+		t.Errorf("release() did not remove the clone after it was released by all of the applications")
+	}
+}
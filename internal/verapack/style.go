@@ -10,6 +10,7 @@ import (
 
 var (
 	lightBlue = lipgloss.Color("#00b3e6")
+	darkBlue  = lipgloss.Color("#00698a")
 	darkGray  = lipgloss.Color("#767676")
 	green     = lipgloss.Color("42")
 	red       = lipgloss.Color("9")
@@ -18,6 +19,7 @@ var (
 	redForeground       = lipgloss.NewStyle().Foreground(red)
 	lightBlueForeground = lipgloss.NewStyle().Foreground(lightBlue)
 	darkGrayForeground  = lipgloss.NewStyle().Foreground(darkGray)
+	darkBlueForeground  = lipgloss.NewStyle().Foreground(darkBlue)
 
 	defaultSpinnerOpts = []spinner.Option{
 		spinner.WithSpinner(spinner.Spinner{
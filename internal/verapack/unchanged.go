@@ -0,0 +1,360 @@
+package verapack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DanCreative/veracode-go/veracode"
+	"github.com/DanCreative/verapack/internal/components/reportcard"
+	"github.com/goccy/go-yaml"
+)
+
+// scanStateLock serializes the updates of the scan state file by the applications that are scanned concurrently.
+var scanStateLock sync.Mutex
+
+// scanRecord is the last successful scan of an application, which is recorded in the scan state file.
+type scanRecord struct {
+	Fingerprint string    `yaml:"fingerprint"`
+	ScannedAt   time.Time `yaml:"scanned_at"`
+}
+
+// getScanStatePath returns the path of the file that contains the last successful scan of every application.
+func getScanStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".veracode", "verapack", "state", "scans.yaml"), nil
+}
+
+// scanStateKey returns the key of the application in the scan state file. Policy and sandbox scans, and the
+// scans of different sandboxes, are recorded separately.
+func scanStateKey(options Options) string {
+	if options.ScanType == ScanTypeSandbox {
+		return fmt.Sprintf("%s/%s/%s", options.AppName, options.ScanType, options.SandboxName)
+	}
+
+	return fmt.Sprintf("%s/%s", options.AppName, options.ScanType)
+}
+
+func readScanState(statePath string) (map[string]scanRecord, error) {
+	state := make(map[string]scanRecord)
+
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// lastScan returns the last successful scan of the application, and whether it has one.
+func lastScan(statePath string, options Options) (scanRecord, bool, error) {
+	scanStateLock.Lock()
+	defer scanStateLock.Unlock()
+
+	state, err := readScanState(statePath)
+	if err != nil {
+		return scanRecord{}, false, err
+	}
+
+	record, ok := state[scanStateKey(options)]
+	return record, ok, nil
+}
+
+// recordScan records a successful scan of the application.
+func recordScan(statePath string, options Options, record scanRecord) error {
+	scanStateLock.Lock()
+	defer scanStateLock.Unlock()
+
+	state, err := readScanState(statePath)
+	if err != nil {
+		return err
+	}
+
+	state[scanStateKey(options)] = record
+
+	content, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(statePath, content, 0600)
+}
+
+// scanFingerprint returns a hash that identifies what is scanned. It is determined before the application is
+// packaged, because the archives of the auto-packager are not reproducible. The source is identified by the
+// commit SHA if it was cloned, or if it is a local repository without changes, and otherwise by the paths and
+// contents of its files. The options that change what is packaged or scanned are hashed together with the source.
+func scanFingerprint(options Options) (string, error) {
+	source, err := sourceFingerprint(options)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(struct {
+		Source                         string
+		SourceSubdir                   string
+		SparsePaths                    []string
+		Include                        []string
+		Exclude                        []string
+		Build                          *BuildOptions
+		IncludeModules                 []string
+		ExcludeModules                 []string
+		ScanAllNonFatalTopLevelModules bool
+		WrapperArgs                    []string
+	}{source, options.SourceSubdir, options.SparsePaths, options.Include, options.Exclude, options.Build, options.IncludeModules, options.ExcludeModules, options.ScanAllNonFatalTopLevelModules, options.WrapperArgs})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(content)
+
+	kind, _, _ := strings.Cut(source, ":")
+	return kind + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+// sourceFingerprint returns the commit SHA of the source, prefixed with "git:", or a hash of its files, prefixed
+// with "sha256:".
+func sourceFingerprint(options Options) (string, error) {
+	if options.Git.SHA != "" {
+		return "git:" + options.Git.SHA, nil
+	}
+
+	roots := options.ArtefactPaths
+
+	if options.PackageSource != "" {
+		if sha, ok := cleanCommit(options.PackageSource); ok {
+			return "git:" + sha, nil
+		}
+
+		roots = []string{options.PackageSource}
+	}
+
+	hash := sha256.New()
+
+	for _, root := range roots {
+		if err := hashFiles(hash, root); err != nil {
+			return "", err
+		}
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// cleanCommit returns the SHA of the checked out commit, if dir is in a git repository that does not have any
+// uncommitted changes or untracked files.
+func cleanCommit(dir string) (string, bool) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return "", false
+	}
+
+	status, err := runGitCommand(gitPath, nil, nil, "-C", dir, "status", "--porcelain")
+	if err != nil || strings.TrimSpace(status) != "" {
+		return "", false
+	}
+
+	sha, err := runGitCommand(gitPath, nil, nil, "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(sha), true
+}
+
+// hashFiles writes the paths and contents of the files in root to w. The .git directories are skipped, and the
+// targets of symlinks are written instead of their contents.
+func hashFiles(w io.Writer, root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(filepath.Dir(root), p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%s\x00", filepath.ToSlash(rel))
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(w, target)
+			return err
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		return err
+	})
+}
+
+// scanFrequencyDue returns whether a scan is due after lastScan, according to the scan frequency of a policy.
+// Frequencies that can not be determined locally are always due.
+func scanFrequencyDue(frequency veracode.PolicyScanFrequency, lastScan, now time.Time) bool {
+	var next time.Time
+
+	switch frequency {
+	case veracode.PolicyScanFrequencyNotRequired, veracode.PolicyScanFrequencyOnce:
+		return false
+	case veracode.PolicyScanFrequencyWeekly:
+		next = lastScan.AddDate(0, 0, 7)
+	case veracode.PolicyScanFrequencyMonthly:
+		next = lastScan.AddDate(0, 1, 0)
+	case veracode.PolicyScanFrequencyQuarterly:
+		next = lastScan.AddDate(0, 3, 0)
+	case veracode.PolicyScanFrequencySemiAnnually:
+		next = lastScan.AddDate(0, 6, 0)
+	case veracode.PolicyScanFrequencyAnnually:
+		next = lastScan.AddDate(1, 0, 0)
+	case veracode.PolicyScanFrequencyEvery18Months:
+		next = lastScan.AddDate(0, 18, 0)
+	case veracode.PolicyScanFrequencyEvery2Years:
+		next = lastScan.AddDate(2, 0, 0)
+	case veracode.PolicyScanFrequencyEvery3Years:
+		next = lastScan.AddDate(3, 0, 0)
+	default:
+		return true
+	}
+
+	return !now.Before(next)
+}
+
+// policyScanDue returns whether the policy of the application requires a new static scan after lastScan, and
+// the frequency of the policy.
+func policyScanDue(ctx context.Context, client *veracode.Client, appName string, lastScan time.Time) (bool, veracode.PolicyScanFrequency, error) {
+	appList, _, err := client.Application.ListApplications(ctx, veracode.ListApplicationOptions{Name: appName})
+	if err != nil {
+		return true, "", err
+	}
+
+	var policyGuid string
+	for _, app := range appList {
+		if strings.EqualFold(app.Profile.Name, appName) && len(app.Profile.Policies) > 0 {
+			policyGuid = app.Profile.Policies[0].Guid
+		}
+	}
+
+	if policyGuid == "" {
+		return true, "", fmt.Errorf("could not find the policy of the application with name: '%s'", appName)
+	}
+
+	policy, _, err := client.Policy.GetPolicy(ctx, policyGuid)
+	if err != nil {
+		return true, "", err
+	}
+
+	for _, rule := range policy.ScanFrequencyRules {
+		if rule.ScanType == veracode.PolicyScanTypeStatic || rule.ScanType == veracode.PolicyScanTypeAny {
+			return scanFrequencyDue(rule.Frequency, lastScan, time.Now()), rule.Frequency, nil
+		}
+	}
+
+	// The policy does not require static scans.
+	return false, veracode.PolicyScanFrequencyNotRequired, nil
+}
+
+// checkUnchanged returns whether the application can be skipped, because the fingerprint did not change since its
+// last successful scan and its policy does not require a new scan, and a description of the decision.
+// Any error results in the application being scanned.
+func checkUnchanged(ctx context.Context, client *veracode.Client, statePath string, options Options, fingerprint string) (bool, string) {
+	record, ok, err := lastScan(statePath, options)
+	if err != nil {
+		return false, fmt.Sprintf("The last scan could not be read, therefore the application is scanned: %s", err)
+	}
+
+	if !ok {
+		return false, "The application does not have a recorded scan."
+	}
+
+	if record.Fingerprint != fingerprint {
+		return false, fmt.Sprintf("The application changed since the last scan on %s.", record.ScannedAt.Format(time.DateTime))
+	}
+
+	if options.ScanType == ScanTypePolicy {
+		due, frequency, err := policyScanDue(ctx, client, options.AppName, record.ScannedAt)
+		if err != nil {
+			return false, fmt.Sprintf("The scan frequency of the policy could not be determined, therefore the application is scanned: %s", err)
+		}
+
+		if due {
+			return false, fmt.Sprintf("The application did not change since the last scan on %s, but the policy requires a %s scan.", record.ScannedAt.Format(time.DateTime), strings.ToLower(string(frequency)))
+		}
+	}
+
+	return true, fmt.Sprintf("The application did not change since the last scan on %s.\nFingerprint: %s", record.ScannedAt.Format(time.DateTime), fingerprint)
+}
+
+// skipUnchangedTasks completes the remaining tasks of an application that is not scanned, because it did not
+// change. The application is checked before it is packaged, therefore the packaging tasks are skipped. The upload
+// task shows the "Unchanged" status, and the tasks that depend on the scan are skipped.
+func skipUnchangedTasks(options Options, packageOutputBaseDirectory, out string, appId int, reporter reporter, writer io.Writer) error {
+	skip := reportcard.TaskResultMsg{Status: reportcard.Skip, Index: appId}
+
+	for _, has := range []func(Options) bool{hasPrePackageHookTask, hasPackageTask, hasPostPackageHookTask, hasInspectTask, hasPreUploadHookTask} {
+		if has(options) {
+			reporter.Send(skip)
+		}
+	}
+
+	fmt.Fprintf(writer, "BEGIN (%s)\n%s\nEND (%s)\n", columnUpload, out, columnUpload)
+	reporter.Send(reportcard.TaskResultMsg{
+		Status:              reportcard.Success,
+		Index:               appId,
+		Output:              out,
+		CustomSuccessStatus: reportcard.CustomTaskStatus{Message: "Unchanged", ForegroundColour: "#767676"},
+	})
+
+	if err := cleanupTask(options, packageOutputBaseDirectory, appId, reporter, writer); err != nil {
+		return err
+	}
+
+	for _, has := range []func(Options) bool{hasResultTask, hasPromoteTask, hasPolicyTask, hasPostScanHookTask} {
+		if has(options) {
+			reporter.Send(skip)
+		}
+	}
+
+	return nil
+}
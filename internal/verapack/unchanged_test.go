@@ -0,0 +1,152 @@
+package verapack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DanCreative/veracode-go/veracode"
+)
+
+func TestScanFrequencyDue(t *testing.T) {
+	last := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency veracode.PolicyScanFrequency
+		now       time.Time
+		want      bool
+	}{
+		{veracode.PolicyScanFrequencyWeekly, last.AddDate(0, 0, 6), false},
+		{veracode.PolicyScanFrequencyWeekly, last.AddDate(0, 0, 7), true},
+		{veracode.PolicyScanFrequencyMonthly, last.AddDate(0, 0, 20), false},
+		{veracode.PolicyScanFrequencyMonthly, last.AddDate(0, 1, 1), true},
+		{veracode.PolicyScanFrequencyOnce, last.AddDate(5, 0, 0), false},
+		{veracode.PolicyScanFrequencyNotRequired, last.AddDate(5, 0, 0), false},
+		{veracode.PolicyScanFrequencySetByVLPolicy, last, true},
+	}
+	for _, tt := range tests {
+		if got := scanFrequencyDue(tt.frequency, last, tt.now); got != tt.want {
+			t.Errorf("scanFrequencyDue(%s, %s) = %v, want %v", tt.frequency, tt.now.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestScanFingerprint(t *testing.T) {
+	dir := newTestArtefacts(t, "app.jar", "lib/dep.jar")
+	options := Options{ArtefactPaths: []string{dir}}
+
+	first, err := scanFingerprint(options)
+	if err != nil {
+		t.Fatalf("scanFingerprint() error = %v", err)
+	}
+
+	if second, _ := scanFingerprint(options); second != first {
+		t.Errorf("scanFingerprint() = %s, want the same fingerprint %s for the same artefacts", second, first)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "app.jar"), []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, _ := scanFingerprint(options); changed == first {
+		t.Errorf("scanFingerprint() did not change after an artefact changed")
+	}
+}
+
+func TestScanFingerprintOptions(t *testing.T) {
+	base := Options{Git: GitInfo{SHA: "0123456789abcdef"}}
+
+	first, err := scanFingerprint(base)
+	if err != nil {
+		t.Fatalf("scanFingerprint() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+	}{
+		{name: "commit", modify: func(o *Options) { o.Git.SHA = "fedcba9876543210" }},
+		{name: "source_subdir", modify: func(o *Options) { o.SourceSubdir = "api" }},
+		{name: "include", modify: func(o *Options) { o.Include = []string{"*.jar"} }},
+		{name: "include_modules", modify: func(o *Options) { o.IncludeModules = []string{"app.jar"} }},
+		{name: "exclude_modules", modify: func(o *Options) { o.ExcludeModules = []string{"app.jar"} }},
+		{name: "scan_all_non_fatal_top_level_modules", modify: func(o *Options) { o.ScanAllNonFatalTopLevelModules = true }},
+		{name: "wrapper_args", modify: func(o *Options) { o.WrapperArgs = []string{"-scanpollinginterval", "60"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := base
+			tt.modify(&options)
+
+			if got, _ := scanFingerprint(options); got == first {
+				t.Errorf("scanFingerprint() = %s, want a different fingerprint", got)
+			}
+		})
+	}
+}
+
+func TestScanFingerprintLocalRepository(t *testing.T) {
+	dir := newTestRepository(t, "main.go")
+	options := Options{PackageSource: dir, Type: Directory}
+
+	first, err := scanFingerprint(options)
+	if err != nil {
+		t.Fatalf("scanFingerprint() error = %v", err)
+	}
+
+	if !strings.HasPrefix(first, "git:") {
+		t.Errorf("scanFingerprint() = %s, want the fingerprint of the commit", first)
+	}
+
+	// The uncommitted changes are hashed.
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dirty, err := scanFingerprint(options)
+	if err != nil {
+		t.Fatalf("scanFingerprint() error = %v", err)
+	}
+
+	if !strings.HasPrefix(dirty, "sha256:") {
+		t.Errorf("scanFingerprint() = %s, want the fingerprint of the files", dirty)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), []byte("changed again"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, _ := scanFingerprint(options); changed == dirty {
+		t.Errorf("scanFingerprint() did not change after a file changed")
+	}
+}
+
+func TestCheckUnchanged(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "scans.yaml")
+	options := Options{AppName: "App", ScanType: ScanTypeSandbox, SandboxName: "Release Candidate"}
+
+	if unchanged, reason := checkUnchanged(context.Background(), nil, statePath, options, "sha256:a"); unchanged {
+		t.Errorf("checkUnchanged() = true, want false without a recorded scan: %s", reason)
+	}
+
+	if err := recordScan(statePath, options, scanRecord{Fingerprint: "sha256:a", ScannedAt: time.Now()}); err != nil {
+		t.Fatalf("recordScan() error = %v", err)
+	}
+
+	if unchanged, reason := checkUnchanged(context.Background(), nil, statePath, options, "sha256:a"); !unchanged {
+		t.Errorf("checkUnchanged() = false, want true for the same fingerprint: %s", reason)
+	}
+
+	if unchanged, _ := checkUnchanged(context.Background(), nil, statePath, options, "sha256:b"); unchanged {
+		t.Errorf("checkUnchanged() = true, want false for a different fingerprint")
+	}
+
+	// The scans of another sandbox are recorded separately.
+	options.SandboxName = "Feature"
+	if unchanged, _ := checkUnchanged(context.Background(), nil, statePath, options, "sha256:a"); unchanged {
+		t.Errorf("checkUnchanged() = true, want false for another sandbox")
+	}
+}
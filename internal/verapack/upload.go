@@ -7,6 +7,7 @@ import (
 	"io"
 	"os/exec"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -19,7 +20,7 @@ func uploadOptionsToArgs(options Options) []string {
 	// This is to fix a Java sun.security.provider.certpath.SunCertPathBuilderException
 	// when running the application behind a corporate proxy with its own cert.
 
-	r := make([]string, 0, 30) // capacity is set to 1.5x max number of possible options. Remember to change when adding options.
+	r := make([]string, 0, 42+len(options.WrapperArgs)) // capacity is set to 1.5x max number of possible options. Remember to change when adding options.
 
 	r = append(r,
 		"-Djavax.net.ssl.trustStoreType=WINDOWS-ROOT",
@@ -36,6 +37,32 @@ func uploadOptionsToArgs(options Options) []string {
 	}
 
 	// Optional fields
+	if len(options.IncludeModules) > 0 {
+		r = append(r, "-include", strings.Join(options.IncludeModules, ","))
+	}
+
+	if len(options.ExcludeModules) > 0 {
+		r = append(r, "-exclude", strings.Join(options.ExcludeModules, ","))
+	}
+
+	if options.ScanAllNonFatalTopLevelModules {
+		r = append(r, "-scanallnonfataltoplevelmodules", "true")
+	}
+
+	profile := options.appProfile()
+
+	if len(profile.Teams) > 0 {
+		r = append(r, "-teams", strings.Join(profile.Teams, ","))
+	}
+
+	if profile.BusinessCriticality != "" {
+		r = append(r, "-criticality", profile.BusinessCriticality)
+	}
+
+	if options.LifecycleStage != "" {
+		r = append(r, "-lifecyclestage", options.LifecycleStage)
+	}
+
 	if options.ScanType == ScanTypeSandbox {
 		r = append(r, "-sandboxid", strconv.Itoa(options.SandboxId))
 	}
@@ -44,10 +71,17 @@ func uploadOptionsToArgs(options Options) []string {
 		r = append(r, "-debug")
 	}
 
+	// The arguments are validated by [checkWrapperArgs], therefore they can not override the other arguments.
+	r = append(r, options.WrapperArgs...)
+
 	return r
 }
 
-func UploadAndScanApplication(options Options, writer io.Writer) (string, error) {
+// UploadAndScanApplication runs the Java API wrapper's UploadAndScan action.
+//
+// env is appended to the environment of the wrapper. It is used to pass the credentials of the
+// application's credentials profile, see [ProfileClient].Env.
+func UploadAndScanApplication(options Options, env []string, writer io.Writer) (string, error) {
 	fmt.Fprintf(writer, "BEGIN (%s)\n", columnUpload)
 
 	path, err := exec.LookPath("java")
@@ -56,6 +90,7 @@ func UploadAndScanApplication(options Options, writer io.Writer) (string, error)
 	}
 
 	cmd := exec.Command(path, uploadOptionsToArgs(options)...)
+	cmd.Env = withEnv(env)
 
 	var outBuffer bytes.Buffer
 
@@ -0,0 +1,96 @@
+package verapack
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// versionDateFormat is the format of the Date placeholder in a version template.
+const versionDateFormat = "02 Jan 2006 15:04:05"
+
+// versionTemplateData contains the values of the placeholders that can be used in [Options].Version, e.g.
+//
+//	version: "{{.Branch}}@{{.ShortSHA}} {{.Date}}"
+type versionTemplateData struct {
+	Date     string // Date-time at which the version was rendered.
+	Branch   string // Branch that was cloned.
+	SHA      string // Full SHA of the cloned commit.
+	ShortSHA string // Abbreviated SHA of the cloned commit.
+	Tag      string // Tag that was cloned.
+	AppName  string
+	ScanType string
+}
+
+func newVersionTemplateData(options Options, now time.Time) versionTemplateData {
+	return versionTemplateData{
+		Date:     now.Format(versionDateFormat),
+		Branch:   options.Git.Branch,
+		SHA:      options.Git.SHA,
+		ShortSHA: options.Git.ShortSHA(),
+		Tag:      options.Git.Tag,
+		AppName:  options.AppName,
+		ScanType: string(options.ScanType),
+	}
+}
+
+// isVersionTemplate returns whether the version contains any template actions.
+func isVersionTemplate(version string) bool {
+	return strings.Contains(version, "{{")
+}
+
+func executeVersionTemplate(version string, data versionTemplateData) (string, error) {
+	t, err := template.New("version").Parse(version)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err = t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// RenderVersion renders the application's version template. It is run after the clone step, so that the git
+// metadata is available. If the version is not a template, it is returned as is.
+func RenderVersion(options Options) (string, error) {
+	if !isVersionTemplate(options.Version) {
+		return options.Version, nil
+	}
+
+	return executeVersionTemplate(options.Version, newVersionTemplateData(options, time.Now()))
+}
+
+// validateVersionTemplate is the validation function for validating that the version template can be rendered.
+func validateVersionTemplate(fl validator.FieldLevel) bool {
+	version := fl.Field().String()
+
+	if !isVersionTemplate(version) {
+		return true
+	}
+
+	_, err := executeVersionTemplate(version, versionTemplateData{})
+	return err == nil
+}
+
+// validateVersionUnique is the validation function for validating that the version template renders a different
+// build name on every run. Build names must be unique, and every other placeholder can render the same value for
+// two scans of the same commit, therefore the template has to include the Date placeholder.
+func validateVersionUnique(fl validator.FieldLevel) bool {
+	version := fl.Field().String()
+
+	if !isVersionTemplate(version) {
+		return true
+	}
+
+	now := time.Now()
+
+	a, errA := executeVersionTemplate(version, versionTemplateData{Date: now.Format(versionDateFormat)})
+	b, errB := executeVersionTemplate(version, versionTemplateData{Date: now.Add(time.Second).Format(versionDateFormat)})
+
+	return errA == nil && errB == nil && a != b
+}
@@ -0,0 +1,52 @@
+package verapack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		wantErr string
+	}{
+		{name: "static version", version: "1.0.0"},
+		{name: "template with date", version: "{{.Branch}}@{{.ShortSHA}} {{.Date}}"},
+		{name: "template without date", version: "{{.Tag}}", wantErr: "version_unique"},
+		{name: "unknown placeholder", version: "{{.Date}} {{.Commit}}", wantErr: "version_template"},
+		{name: "invalid syntax", version: "{{.Date", wantErr: "version_template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewValidator()
+
+			err := validate.Var(tt.version, "required,version_template,version_unique")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validate version %s: unexpected error = %v", tt.version, err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validate version %s: error = %v, want %s", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderVersion(t *testing.T) {
+	options := Options{
+		AppName:  "App",
+		ScanType: ScanTypeSandbox,
+		Version:  "{{.AppName}} {{.ScanType}} {{.Branch}}@{{.ShortSHA}} {{.Tag}}",
+		Git:      GitInfo{SHA: "0123456789abcdef", Branch: "main"},
+	}
+
+	got, err := RenderVersion(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "App sandbox main@0123456"; got != want {
+		t.Errorf("RenderVersion() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package verapack
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// lifecycleStages are the values of the lifecycle stage of an application profile.
+	lifecycleStages = []string{
+		"In Development (pre-Alpha)", "Internal or Alpha Testing", "External or Beta Testing",
+		"Deployed", "Maintenance", "Cannot Disclose", "Not Specified",
+	}
+
+	// wrapperArgsReserved are the arguments of the API wrapper that are always set by verapack.
+	wrapperArgsReserved = []string{
+		"action", "appname", "version", "createprofile", "filepath", "sandboxid", "sandboxname", "createsandbox",
+		"debug", "vid", "vkey", "vuser", "vpassword",
+	}
+
+	// wrapperArgsFields maps the arguments of the API wrapper that have an option to the name of the option.
+	wrapperArgsFields = map[string]string{
+		"include":                        "include_modules",
+		"exclude":                        "exclude_modules",
+		"scanallnonfataltoplevelmodules": "scan_all_non_fatal_top_level_modules",
+		"teams":                          "teams",
+		"criticality":                    "criticality",
+		"lifecyclestage":                 "lifecycle_stage",
+		"deleteincompletescan":           "on_existing_build",
+	}
+
+	// wrapperArgsAllowed are the other arguments of the UploadAndScan action that can be set in wrapper_args.
+	wrapperArgsAllowed = []string{
+		"autoscan", "businessowner", "businessowneremail", "businessunit", "description", "maxretrycount",
+		"pattern", "policy", "replacement", "scanpollinginterval", "scantimeout", "selected", "selectedpreviously",
+		"toplevel", "logfilepath", "useragent", "phost", "pport", "puser", "ppassword",
+	}
+
+	// wrapperArgsBool are the arguments of the API wrapper whose value is true or false.
+	wrapperArgsBool = []string{"autoscan", "selected", "selectedpreviously", "toplevel"}
+
+	// wrapperArgsInt are the arguments of the API wrapper whose value is a positive number.
+	wrapperArgsInt = []string{"maxretrycount", "scanpollinginterval", "scantimeout", "pport"}
+
+	// wrapperArgsConflicts return the name of the option that the argument of the API wrapper conflicts with, or an
+	// empty string if it does not conflict with the options.
+	wrapperArgsConflicts = map[string]func(options Options) string{
+		// The modules are selected by the options, and the result is awaited after the scan was started.
+		"autoscan": func(options Options) string {
+			if name := moduleSelectionOption(options); name != "" {
+				return name
+			}
+
+			switch {
+			case options.WaitForResult:
+				return "wait_for_result"
+			case options.AutoPromote:
+				return "auto_promote"
+			}
+
+			return ""
+		},
+		"selected":           moduleSelectionOption,
+		"selectedpreviously": moduleSelectionOption,
+		"toplevel":           moduleSelectionOption,
+		// The wrapper waits for the scan itself, while verapack waits for the result with scan_timeout. The default
+		// scan_timeout does not conflict, because the user did not set it.
+		"scantimeout": func(options Options) string {
+			if options.ScanTimeout > 0 && !options.defaultScanTimeout {
+				return "scan_timeout"
+			}
+
+			return ""
+		},
+	}
+)
+
+// moduleSelectionOption returns the name of the option that selects the modules of the scan, or an empty string
+// if none of them are set.
+func moduleSelectionOption(options Options) string {
+	switch {
+	case len(options.IncludeModules) > 0:
+		return "include_modules"
+	case len(options.ExcludeModules) > 0:
+		return "exclude_modules"
+	case options.ScanAllNonFatalTopLevelModules:
+		return "scan_all_non_fatal_top_level_modules"
+	}
+
+	return ""
+}
+
+// validateLifecycleStage is the validation function for validating that the value is a lifecycle stage.
+func validateLifecycleStage(fl validator.FieldLevel) bool {
+	return slices.Contains(lifecycleStages, fl.Field().String())
+}
+
+// checkWrapperArgs returns a description of the first problem with the additional arguments of the API wrapper of the
+// options, or an empty string if they are valid. The arguments must be pairs of a flag and its value. Flags that are set
+// by verapack, that have an option, or that conflict with the options that are set, are rejected.
+func checkWrapperArgs(options Options) string {
+	args := options.WrapperArgs
+
+	if len(args)%2 != 0 {
+		return "every argument must be a flag followed by its value"
+	}
+
+	seen := make(map[string]bool, len(args)/2)
+
+	for i := 0; i < len(args); i += 2 {
+		flag, ok := strings.CutPrefix(args[i], "-")
+		if !ok || flag == "" {
+			return fmt.Sprintf("'%s' is not a flag, every argument must be a flag followed by its value", args[i])
+		}
+
+		flag = strings.ToLower(flag)
+
+		switch {
+		case slices.Contains(wrapperArgsReserved, flag):
+			return fmt.Sprintf("'-%s' is set by verapack and can not be overridden", flag)
+		case wrapperArgsFields[flag] != "":
+			return fmt.Sprintf("'-%s' must be set with the '%s' option instead", flag, wrapperArgsFields[flag])
+		case !slices.Contains(wrapperArgsAllowed, flag):
+			return fmt.Sprintf("'-%s' is not a supported argument of the UploadAndScan action", flag)
+		case seen[flag]:
+			return fmt.Sprintf("'-%s' is set more than once", flag)
+		}
+
+		value := args[i+1]
+
+		if slices.Contains(wrapperArgsBool, flag) && value != "true" && value != "false" {
+			return fmt.Sprintf("the value of '-%s' must be true or false, not '%s'", flag, value)
+		}
+
+		if n, err := strconv.Atoi(value); slices.Contains(wrapperArgsInt, flag) && (err != nil || n <= 0) {
+			return fmt.Sprintf("the value of '-%s' must be a positive number, not '%s'", flag, value)
+		}
+
+		if conflicts := wrapperArgsConflicts[flag]; conflicts != nil {
+			if name := conflicts(options); name != "" {
+				return fmt.Sprintf("'-%s' conflicts with the '%s' option", flag, name)
+			}
+		}
+
+		seen[flag] = true
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package verapack
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckWrapperArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		options Options
+		valid   bool
+	}{
+		{args: nil, valid: true},
+		{args: []string{"-scantimeout", "60", "-autoscan", "true"}, valid: true},
+		{args: []string{"-ScanTimeout", "60"}, valid: true},
+		{args: []string{"-scantimeout"}, valid: false},
+		{args: []string{"scantimeout", "60"}, valid: false},
+		{args: []string{"-appname", "Other App"}, valid: false},
+		{args: []string{"-include", "*.war"}, valid: false},
+		{args: []string{"-unknown", "true"}, valid: false},
+		{args: []string{"-scantimeout", "60", "-scantimeout", "30"}, valid: false},
+		{args: []string{"-scantimeout", "abc"}, valid: false},
+		{args: []string{"-autoscan", "no"}, valid: false},
+		{args: []string{"-autoscan", "false"}, options: Options{WaitForResult: true, ScanTimeout: 120}, valid: false},
+		{args: []string{"-toplevel", "true"}, options: Options{IncludeModules: []string{"app.war"}}, valid: false},
+		{args: []string{"-toplevel", "true"}, valid: true},
+		{args: []string{"-scantimeout", "60"}, options: Options{ScanTimeout: 120}, valid: false},
+		{args: []string{"-scantimeout", "60"}, options: Options{ScanTimeout: 120, defaultScanTimeout: true}, valid: true},
+	}
+	for _, tt := range tests {
+		tt.options.WrapperArgs = tt.args
+		if got := checkWrapperArgs(tt.options); (got == "") != tt.valid {
+			t.Errorf("checkWrapperArgs(%v) = %q, want valid %v", tt.args, got, tt.valid)
+		}
+	}
+}
+
+func TestCheckWrapperArgsDefaultScanTimeout(t *testing.T) {
+	for content, valid := range map[string]bool{
+		"applications:\n  - app_name: App\n    on_existing_build: wait\n    wrapper_args: [-scantimeout, '60']":                     true,
+		"applications:\n  - app_name: App\n    wait_for_result: true\n    scan_timeout: 90\n    wrapper_args: [-scantimeout, '60']": false,
+	} {
+		c, err := SetDefaults([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := checkWrapperArgs(c.Applications[0]); (got == "") != valid {
+			t.Errorf("checkWrapperArgs() of %q = %q, want valid %v", content, got, valid)
+		}
+	}
+}
+
+func TestUploadOptionsToArgsWrapperOptions(t *testing.T) {
+	verbose := false
+	options := Options{
+		AppName:        "App",
+		Version:        "1.0.0",
+		CreateProfile:  new(bool),
+		Verbose:        &verbose,
+		Teams:          []string{"Team A", "Team B"},
+		Criticality:    "High",
+		LifecycleStage: "Deployed",
+		WrapperArgs:    []string{"-scantimeout", "60"},
+	}
+
+	args := uploadOptionsToArgs(options)
+
+	for _, want := range [][]string{{"-teams", "Team A,Team B"}, {"-criticality", "High"}, {"-lifecyclestage", "Deployed"}} {
+		if k := slices.Index(args, want[0]); k == -1 || args[k+1] != want[1] {
+			t.Errorf("uploadOptionsToArgs() = %v, want %s %q", args, want[0], want[1])
+		}
+	}
+
+	if !slices.Equal(args[len(args)-2:], options.WrapperArgs) {
+		t.Errorf("uploadOptionsToArgs() = %v, want the wrapper args at the end", args)
+	}
+}